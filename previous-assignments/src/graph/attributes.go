@@ -0,0 +1,310 @@
+package graph
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Attributes holds optional typed vertex and edge data of a Graph, kept
+// separate from the vertex Value (which is used as the color). Each field
+// is nil if the graph doesn't have that attribute
+type Attributes struct {
+	VertexWeights []int    // weight of each vertex
+	EdgeWeights   [][]int  // weight of each edge, parallel to Vertex.Adj
+	VertexLabels  []string // label (e.g., name) of each vertex
+}
+
+// Attribute flags in the header line of the text format, which is "N" for a
+// graph without attributes or "N FLAGS" otherwise; the present attributes
+// follow the adjacency list of each vertex line in this order, e.g.,
+// "value;adj,adj;weight;eweight,eweight;label" for "N wel"
+const (
+	ATTR_VERTEX_WEIGHTS = 'w'
+	ATTR_EDGE_WEIGHTS   = 'e'
+	ATTR_VERTEX_LABELS  = 'l'
+)
+
+// attrFlagOrder is the order of attributes in the text format
+const attrFlagOrder = "wel"
+
+// NewAttributes allocates the given attributes for a graph's vertices;
+// vertex and edge weights default to 1 and labels to the empty string
+func (g *Graph) NewAttributes(vertexWeights, edgeWeights,
+	vertexLabels bool) {
+
+	nVertices := len(g.Vertices)
+	g.Attrs = &Attributes{}
+
+	if vertexWeights {
+		g.Attrs.VertexWeights = make([]int, nVertices)
+		for i := range g.Attrs.VertexWeights {
+			g.Attrs.VertexWeights[i] = 1
+		}
+	}
+	if edgeWeights {
+		g.Attrs.EdgeWeights = make([][]int, nVertices)
+		for i := range g.Attrs.EdgeWeights {
+			g.Attrs.EdgeWeights[i] = make([]int, len(g.Vertices[i].Adj))
+			for k := range g.Attrs.EdgeWeights[i] {
+				g.Attrs.EdgeWeights[i][k] = 1
+			}
+		}
+	}
+	if vertexLabels {
+		g.Attrs.VertexLabels = make([]string, nVertices)
+	}
+}
+
+// VertexWeight returns the weight of vertex i, or 1 if the graph has no
+// vertex weights
+func (g *Graph) VertexWeight(i int) int {
+	if g.Attrs == nil || g.Attrs.VertexWeights == nil {
+		return 1
+	}
+	return g.Attrs.VertexWeights[i]
+}
+
+// EdgeWeight returns the weight of the edge from vertex i to its k-th
+// neighbor, or 1 if the graph has no edge weights
+func (g *Graph) EdgeWeight(i, k int) int {
+	if g.Attrs == nil || g.Attrs.EdgeWeights == nil {
+		return 1
+	}
+	return g.Attrs.EdgeWeights[i][k]
+}
+
+// VertexLabel returns the label of vertex i, or the empty string if the
+// graph has no labels
+func (g *Graph) VertexLabel(i int) string {
+	if g.Attrs == nil || g.Attrs.VertexLabels == nil {
+		return ""
+	}
+	return g.Attrs.VertexLabels[i]
+}
+
+// attrFlags returns the text format header flags of a graph's attributes
+func (g *Graph) attrFlags() string {
+	if g.Attrs == nil {
+		return ""
+	}
+
+	flags := ""
+	if g.Attrs.VertexWeights != nil {
+		flags += string(ATTR_VERTEX_WEIGHTS)
+	}
+	if g.Attrs.EdgeWeights != nil {
+		flags += string(ATTR_EDGE_WEIGHTS)
+	}
+	if g.Attrs.VertexLabels != nil {
+		flags += string(ATTR_VERTEX_LABELS)
+	}
+	return flags
+}
+
+// ParseTextHeader parses the header line of the text format, returning the
+// number of vertices and the attribute flags
+func ParseTextHeader(text string) (int, string, error) {
+	fields := strings.Fields(text)
+	if len(fields) < 1 || len(fields) > 2 {
+		return 0, "", &ValidationError{ERR_MALFORMED_LINE, 1,
+			fmt.Sprintf("invalid header %q", text)}
+	}
+
+	nVertices, err := strconv.Atoi(fields[0])
+	if err != nil || nVertices < 0 {
+		return 0, "", &ValidationError{ERR_MALFORMED_LINE, 1,
+			fmt.Sprintf("invalid vertex count %q", fields[0])}
+	}
+
+	// flags must be a subsequence of attrFlagOrder
+	flags := ""
+	if len(fields) == 2 {
+		flags = fields[1]
+		order := attrFlagOrder
+		for _, flag := range flags {
+			index := strings.IndexRune(order, flag)
+			if index == -1 {
+				return 0, "", &ValidationError{ERR_MALFORMED_LINE, 1,
+					fmt.Sprintf("invalid attribute flags %q", flags)}
+			}
+			order = order[index+1:]
+		}
+	}
+
+	return nVertices, flags, nil
+}
+
+// textHeader returns the header line of the text format for a graph
+func (g *Graph) textHeader() string {
+	header := strconv.Itoa(len(g.Vertices))
+	if flags := g.attrFlags(); flags != "" {
+		header += " " + flags
+	}
+	return header
+}
+
+// newAttrsFromFlags allocates a graph's attributes from text header flags
+func (g *Graph) newAttrsFromFlags(flags string) {
+	if flags == "" {
+		return
+	}
+	g.NewAttributes(strings.ContainsRune(flags, ATTR_VERTEX_WEIGHTS),
+		strings.ContainsRune(flags, ATTR_EDGE_WEIGHTS),
+		strings.ContainsRune(flags, ATTR_VERTEX_LABELS))
+}
+
+// setTextAttrs parses the attribute components of vertex i's line in the
+// text format; missing trailing components keep their default values
+func (g *Graph) setTextAttrs(i int, components []string, flags string,
+	lineNo int) error {
+
+	malformed := func(msg string) error {
+		return &ValidationError{ERR_MALFORMED_LINE, lineNo, msg}
+	}
+
+	for k, flag := range flags {
+		if k >= len(components) {
+			break
+		}
+		component := components[k]
+
+		switch flag {
+		case ATTR_VERTEX_WEIGHTS:
+			if component == "" {
+				continue
+			}
+			weight, err := strconv.Atoi(component)
+			if err != nil {
+				return malformed(fmt.Sprintf("invalid vertex weight %q",
+					component))
+			}
+			g.Attrs.VertexWeights[i] = weight
+
+		case ATTR_EDGE_WEIGHTS:
+			weights := make([]int, len(g.Vertices[i].Adj))
+			if component != "" {
+				weightComponents := strings.Split(component, ",")
+				if len(weightComponents) != len(weights) {
+					return malformed(fmt.Sprintf("%d edge weights for %d "+
+						"neighbors", len(weightComponents), len(weights)))
+				}
+				for l, weightComponent := range weightComponents {
+					weight, err := strconv.Atoi(weightComponent)
+					if err != nil {
+						return malformed(fmt.Sprintf("invalid edge weight "+
+							"%q", weightComponent))
+					}
+					weights[l] = weight
+				}
+			} else {
+				for l := range weights {
+					weights[l] = 1
+				}
+			}
+			g.Attrs.EdgeWeights[i] = weights
+
+		case ATTR_VERTEX_LABELS:
+			g.Attrs.VertexLabels[i] = component
+		}
+	}
+
+	// edge weights default to 1 if the component is missing entirely
+	if g.Attrs.EdgeWeights != nil &&
+		len(g.Attrs.EdgeWeights[i]) != len(g.Vertices[i].Adj) {
+
+		g.Attrs.EdgeWeights[i] = make([]int, len(g.Vertices[i].Adj))
+		for l := range g.Attrs.EdgeWeights[i] {
+			g.Attrs.EdgeWeights[i][l] = 1
+		}
+	}
+
+	return nil
+}
+
+// textAttrs returns the attribute components of vertex i's line in the text
+// format, including the leading separator
+func (g *Graph) textAttrs(i int) string {
+	if g.Attrs == nil {
+		return ""
+	}
+
+	s := ""
+	if g.Attrs.VertexWeights != nil {
+		s += ";" + strconv.Itoa(g.Attrs.VertexWeights[i])
+	}
+	if g.Attrs.EdgeWeights != nil {
+		s += ";"
+		for k, weight := range g.Attrs.EdgeWeights[i] {
+			if k > 0 {
+				s += ","
+			}
+			s += strconv.Itoa(weight)
+		}
+	}
+	if g.Attrs.VertexLabels != nil {
+		s += ";" + g.Attrs.VertexLabels[i]
+	}
+	return s
+}
+
+// TextVertexWeight returns the vertex weight on a vertex line of the text
+// format with the given header flags, or 1 if there are no vertex weights;
+// this lets streaming readers (e.g., the proj2 server) weigh vertices
+// without loading the whole graph
+func TextVertexWeight(text, flags string) (int, error) {
+	if !strings.ContainsRune(flags, ATTR_VERTEX_WEIGHTS) {
+		return 1, nil
+	}
+
+	// vertex weights are always the first attribute
+	components := strings.SplitN(text, ";", 4)
+	if len(components) < 3 || components[2] == "" {
+		return 1, nil
+	}
+	return strconv.Atoi(components[2])
+}
+
+// WeightedRanges splits vertices 0 to len(weights)-1 into nParts contiguous
+// ranges of about equal total weight, e.g., for load-balanced partitioning;
+// range k is [ranges[k], ranges[k+1]). Each range ends at the first vertex
+// where the cumulative weight reaches its share, and if all weights are 0,
+// vertices are counted instead
+func WeightedRanges(weights []int, nParts int) ([]int, error) {
+	if nParts < 1 {
+		return nil, fmt.Errorf("graph: can't split vertices into %d ranges",
+			nParts)
+	}
+	total := 0
+	for i, weight := range weights {
+		if weight < 0 {
+			return nil, fmt.Errorf("graph: negative weight %d of vertex %d",
+				weight, i)
+		}
+		total += weight
+	}
+	unweighted := total == 0
+	if unweighted {
+		total = len(weights)
+	}
+	weightOf := func(i int) int {
+		if unweighted {
+			return 1
+		}
+		return weights[i]
+	}
+
+	ranges := make([]int, nParts+1)
+	prefix, k := 0, 1
+	for i := range weights {
+		for k < nParts && prefix*nParts >= k*total {
+			ranges[k] = i
+			k++
+		}
+		prefix += weightOf(i)
+	}
+	for ; k <= nParts; k++ {
+		ranges[k] = len(weights)
+	}
+	return ranges, nil
+}
@@ -0,0 +1,233 @@
+package graph
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+)
+
+// The binary graph format is a compact alternative to the line-based text
+// format used by Load and Dump. All integers are little-endian:
+//
+//	magic      [4]byte   "GRPB"
+//	version    uint16    BinaryVersion
+//	flags      uint16    BINARY_FLAG_* bits
+//	nVertices  uint32
+//	nEdges     uint32    number of adjacency entries (2x undirected edges)
+//	offsets    [nVertices+1]int32
+//	adj        [nEdges]int32
+//	colors     [nVertices]int32   (only if BINARY_FLAG_COLORS is set)
+//	checksum   uint32    CRC-32 (IEEE) of all preceding bytes
+//
+// This is the same layout as CSR, so a binary file can be read straight
+// into one. Attributes aren't stored, so graphs that have them must use the
+// text format
+
+// BinaryMagic is the header that identifies a binary graph file
+var BinaryMagic = []byte("GRPB")
+
+// BinaryVersion is the current version of the binary graph format
+const BinaryVersion = uint16(1)
+
+const (
+	// BINARY_FLAG_COLORS indicates that vertex colors (values) are stored
+	BINARY_FLAG_COLORS = uint16(1 << iota)
+)
+
+var (
+	// ErrBadMagic is returned when a binary graph file has the wrong header
+	ErrBadMagic = errors.New("graph: not a binary graph file")
+	// ErrBadChecksum is returned when a binary graph file is corrupted
+	ErrBadChecksum = errors.New("graph: binary graph checksum mismatch")
+	// ErrBinaryAttributes is returned when writing a graph with attributes
+	// in the binary format, which has no room for them
+	ErrBinaryAttributes = errors.New("graph: the binary format can't " +
+		"store attributes, use the text format")
+)
+
+// binaryHeader is the fixed-size header of the binary graph format
+type binaryHeader struct {
+	Magic     [4]byte
+	Version   uint16
+	Flags     uint16
+	NVertices uint32
+	NEdges    uint32
+}
+
+// IsBinary reports whether a reader's next bytes are the binary graph magic
+// header, without consuming them
+func IsBinary(reader *bufio.Reader) bool {
+	magic, err := reader.Peek(len(BinaryMagic))
+	return err == nil && bytes.Equal(magic, BinaryMagic)
+}
+
+// DumpBinary writes a graph to file in the binary format; vertex values are
+// only written if withColors is set. Graphs with attributes are rejected
+// with ErrBinaryAttributes rather than silently losing them
+func (g *Graph) DumpBinary(writer io.Writer, withColors bool) error {
+	if g.attrFlags() != "" {
+		return ErrBinaryAttributes
+	}
+	return NewCSR(g).DumpBinary(writer, withColors)
+}
+
+// DumpBinary writes a CSR to file in the binary format; colors are only
+// written if withColors is set
+func (c *CSR) DumpBinary(writer io.Writer, withColors bool) error {
+	checksum := crc32.NewIEEE()
+	w := io.MultiWriter(writer, checksum)
+
+	header := binaryHeader{
+		Version:   BinaryVersion,
+		NVertices: uint32(c.NumVertices()),
+		NEdges:    uint32(len(c.Adj)),
+	}
+	copy(header.Magic[:], BinaryMagic)
+	if withColors {
+		header.Flags |= BINARY_FLAG_COLORS
+	}
+
+	sections := []interface{}{header, c.Offsets, c.Adj}
+	if withColors {
+		sections = append(sections, c.Colors)
+	}
+	for _, section := range sections {
+		if err := binary.Write(w, binary.LittleEndian, section); err != nil {
+			return err
+		}
+	}
+
+	return binary.Write(writer, binary.LittleEndian, checksum.Sum32())
+}
+
+// LoadBinary reads a graph in the binary format from file
+func LoadBinary(reader io.Reader) (*Graph, error) {
+	c, err := LoadBinaryCSR(reader)
+	if err != nil {
+		return nil, err
+	}
+	return c.ToGraph(), nil
+}
+
+// LoadBinaryCSR reads a graph in the binary format from file directly into a
+// CSR, without building the intermediate Graph
+func LoadBinaryCSR(reader io.Reader) (*CSR, error) {
+	checksum := crc32.NewIEEE()
+	r := io.TeeReader(reader, checksum)
+
+	header, err := readBinaryHeader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// the counts are untrusted until the checksum is verified, so the
+	// sections are read incrementally, and a corrupted count fails when the
+	// input runs out rather than allocating up front
+	var c CSR
+	if c.Offsets, err = readInt32s(r, int(header.NVertices)+1); err != nil {
+		return nil, err
+	}
+	if c.Adj, err = readInt32s(r, int(header.NEdges)); err != nil {
+		return nil, err
+	}
+	if header.Flags&BINARY_FLAG_COLORS != 0 {
+		c.Colors, err = readInt32s(r, int(header.NVertices))
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		c.Colors = make([]int32, header.NVertices)
+	}
+
+	// checksum is read from the underlying reader so it isn't hashed itself
+	sum := checksum.Sum32()
+	var expected uint32
+	if err := binary.Read(reader, binary.LittleEndian, &expected); err != nil {
+		return nil, err
+	}
+	if sum != expected {
+		return nil, ErrBadChecksum
+	}
+
+	// sanity check offsets and neighbors so that later accesses can't panic
+	if err := checkBinaryOffsets(header, c.Offsets); err != nil {
+		return nil, err
+	}
+	if err := checkBinaryNeighbors(header, c.Adj); err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// readBinaryHeader reads and checks the header of the binary format; the
+// counts are only checked to fit an int32
+func readBinaryHeader(reader io.Reader) (binaryHeader, error) {
+	var header binaryHeader
+	if err := binary.Read(reader, binary.LittleEndian, &header); err != nil {
+		return header, err
+	}
+	if !bytes.Equal(header.Magic[:], BinaryMagic) {
+		return header, ErrBadMagic
+	}
+	if header.Version != BinaryVersion {
+		return header, fmt.Errorf("graph: unsupported binary version %d",
+			header.Version)
+	}
+	if header.NVertices >= math.MaxInt32 || header.NEdges > math.MaxInt32 {
+		return header, errors.New("graph: invalid binary graph size")
+	}
+	return header, nil
+}
+
+// checkBinaryOffsets checks that the offsets of the binary format are
+// nondecreasing and span all adjacency entries
+func checkBinaryOffsets(header binaryHeader, offsets []int32) error {
+	if offsets[0] != 0 || offsets[header.NVertices] != int32(header.NEdges) {
+		return errors.New("graph: invalid binary graph offsets")
+	}
+	for i := 0; i < int(header.NVertices); i++ {
+		if offsets[i] > offsets[i+1] {
+			return errors.New("graph: invalid binary graph offsets")
+		}
+	}
+	return nil
+}
+
+// checkBinaryNeighbors checks that adjacency entries of the binary format
+// are vertices of the graph
+func checkBinaryNeighbors(header binaryHeader, adj []int32) error {
+	for _, j := range adj {
+		if j < 0 || j >= int32(header.NVertices) {
+			return fmt.Errorf("graph: invalid neighbor index %d", j)
+		}
+	}
+	return nil
+}
+
+// readInt32s reads n little-endian int32s in chunks, growing the slice only
+// as they are read, so that a bad n can't allocate much more than the input
+// holds
+func readInt32s(reader io.Reader, n int) ([]int32, error) {
+	const chunkSize = 1 << 16
+
+	values := make([]int32, 0)
+	for len(values) < n {
+		start := len(values)
+		end := start + chunkSize
+		if end > n {
+			end = n
+		}
+		values = append(values, make([]int32, end-start)...)
+		err := binary.Read(reader, binary.LittleEndian, values[start:end])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
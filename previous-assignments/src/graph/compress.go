@@ -0,0 +1,118 @@
+package graph
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"github.com/klauspost/compress/zstd"
+	"io"
+)
+
+// Compression is the compression applied to a graph file
+type Compression int
+
+const (
+	// COMPRESSION_NONE means a plain (text or binary) graph file
+	COMPRESSION_NONE Compression = iota
+	// COMPRESSION_GZIP means a gzip-compressed graph file
+	COMPRESSION_GZIP Compression = iota
+	// COMPRESSION_ZSTD means a zstd-compressed graph file; there is no zstd
+	// codec in the standard library, so this uses the vendored
+	// github.com/klauspost/compress/zstd
+	COMPRESSION_ZSTD Compression = iota
+)
+
+// magic bytes of the supported compression formats
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// DetectCompression reports the compression of a reader's next bytes,
+// without consuming them
+func DetectCompression(reader *bufio.Reader) Compression {
+	// Peek returns fewer bytes (and an error) for short files, which simply
+	// won't match
+	magic, _ := reader.Peek(len(zstdMagic))
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		return COMPRESSION_GZIP
+	case bytes.HasPrefix(magic, zstdMagic):
+		return COMPRESSION_ZSTD
+	default:
+		return COMPRESSION_NONE
+	}
+}
+
+// Decompress returns a buffered reader that transparently decompresses a
+// graph file if it is compressed, and passes it through otherwise
+func Decompress(reader io.Reader) (*bufio.Reader, error) {
+	bufReader := bufio.NewReader(reader)
+
+	switch DetectCompression(bufReader) {
+	case COMPRESSION_GZIP:
+		gzipReader, err := gzip.NewReader(bufReader)
+		if err != nil {
+			return nil, err
+		}
+		return bufio.NewReader(gzipReader), nil
+	case COMPRESSION_ZSTD:
+		// with a single goroutine, the decoder decodes synchronously in
+		// Read, so it doesn't need to be closed
+		zstdReader, err := zstd.NewReader(bufReader,
+			zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		return bufio.NewReader(zstdReader), nil
+	default:
+		return bufReader, nil
+	}
+}
+
+// nopWriteCloser adds a no-op Close to a writer, for COMPRESSION_NONE
+type nopWriteCloser struct {
+	io.Writer
+}
+
+// Close implements the io.Closer interface
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// Compress returns a writer that compresses everything written to it with
+// the given compression; it must be closed to flush the compressed stream,
+// but closing it doesn't close the underlying writer
+func Compress(writer io.Writer, compression Compression) (io.WriteCloser,
+	error) {
+
+	switch compression {
+	case COMPRESSION_NONE:
+		return nopWriteCloser{writer}, nil
+	case COMPRESSION_GZIP:
+		return gzip.NewWriter(writer), nil
+	case COMPRESSION_ZSTD:
+		return zstd.NewWriter(writer)
+	default:
+		return nil, fmt.Errorf("graph: unknown compression %d", compression)
+	}
+}
+
+// DumpCompressed writes a graph to file in the text format like Dump, but
+// compressed with the given compression
+func (g *Graph) DumpCompressed(writer io.Writer,
+	compression Compression) error {
+
+	compressor, err := Compress(writer, compression)
+	if err != nil {
+		return err
+	}
+
+	err = g.Dump(compressor)
+	if err != nil {
+		return err
+	}
+
+	return compressor.Close()
+}
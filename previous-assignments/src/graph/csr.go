@@ -0,0 +1,106 @@
+package graph
+
+import (
+	"math"
+)
+
+// CSR is a read-only compressed sparse row representation of a Graph; the
+// neighbors of vertex i are Adj[Offsets[i]:Offsets[i+1]]. Unlike Graph, there
+// is no per-vertex allocation or mutex, and the only mutable part of a CSR is
+// its Colors array
+type CSR struct {
+	Offsets []int32
+	Adj     []int32
+	Colors  []int32
+}
+
+// NewCSR builds a CSR from a Graph, copying its adjacency lists and values
+func NewCSR(g *Graph) *CSR {
+	nVertices := len(g.Vertices)
+
+	// count edges first so that the neighbor array is only allocated once
+	nEdges := 0
+	for i := range g.Vertices {
+		nEdges += len(g.Vertices[i].Adj)
+	}
+	if nVertices > math.MaxInt32 || nEdges > math.MaxInt32 {
+		panic("Graph too large for CSR")
+	}
+
+	c := CSR{
+		Offsets: make([]int32, nVertices+1),
+		Adj:     make([]int32, nEdges),
+		Colors:  make([]int32, nVertices),
+	}
+
+	offset := int32(0)
+	for i := range g.Vertices {
+		v := &g.Vertices[i]
+		c.Offsets[i] = offset
+		c.Colors[i] = int32(v.Value)
+		for _, j := range v.Adj {
+			c.Adj[offset] = int32(j)
+			offset++
+		}
+	}
+	c.Offsets[nVertices] = offset
+
+	return &c
+}
+
+// NumVertices returns the number of vertices in the CSR
+func (c *CSR) NumVertices() int {
+	return len(c.Colors)
+}
+
+// Neighbors returns the neighbors of vertex i; the returned slice aliases
+// the CSR's adjacency array and must not be modified
+func (c *CSR) Neighbors(i int) []int32 {
+	return c.Adj[c.Offsets[i]:c.Offsets[i+1]]
+}
+
+// Degree returns the number of neighbors of vertex i
+func (c *CSR) Degree(i int) int {
+	return int(c.Offsets[i+1] - c.Offsets[i])
+}
+
+// CheckValidColoring checks whether a CSR is appropriately colored
+func (c *CSR) CheckValidColoring() bool {
+	for i := range c.Colors {
+		for _, j := range c.Neighbors(i) {
+			if c.Colors[i] == c.Colors[j] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// CopyColors writes the CSR's colors back into the values of a Graph with
+// the same vertices
+func (c *CSR) CopyColors(g *Graph) {
+	if len(g.Vertices) != len(c.Colors) {
+		panic("Mismatched number of vertices")
+	}
+
+	for i := range c.Colors {
+		g.Vertices[i].Value = int(c.Colors[i])
+	}
+}
+
+// ToGraph converts a CSR back into a Graph, with the CSR's colors as the
+// vertex values
+func (c *CSR) ToGraph() *Graph {
+	g := New(c.NumVertices())
+
+	for i := range g.Vertices {
+		v := &g.Vertices[i]
+		v.Value = int(c.Colors[i])
+		v.Adj = make([]int, c.Degree(i))
+		for k, j := range c.Neighbors(i) {
+			v.Adj[k] = int(j)
+		}
+	}
+
+	return &g
+}
@@ -0,0 +1,113 @@
+package graph
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// LoadDIMACS reads a graph in the DIMACS .col format used by the standard
+// graph coloring benchmarks: comment lines start with "c", the problem line
+// is "p edge N M", and each edge is "e U V" with 1-based vertex indices.
+// Edges are symmetrized and duplicate edges are dropped, since many
+// benchmark files list both directions of an edge
+func LoadDIMACS(reader io.Reader) (*Graph, error) {
+	scanner := bufio.NewScanner(reader)
+	var g *Graph
+
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		fields := strings.Fields(scanner.Text())
+
+		// skip blank and comment lines
+		if len(fields) == 0 || fields[0] == "c" {
+			continue
+		}
+
+		switch fields[0] {
+		case "p":
+			if g != nil {
+				return nil, fmt.Errorf("dimacs line %d: duplicate problem "+
+					"line", lineNo)
+			}
+			if len(fields) != 4 || fields[1] != "edge" {
+				return nil, fmt.Errorf("dimacs line %d: expected "+
+					"\"p edge N M\"", lineNo)
+			}
+			nVertices, err := strconv.Atoi(fields[2])
+			if err != nil || nVertices < 0 {
+				return nil, fmt.Errorf("dimacs line %d: invalid vertex "+
+					"count %q", lineNo, fields[2])
+			}
+			newGraph := New(nVertices)
+			g = &newGraph
+
+		case "e":
+			if g == nil {
+				return nil, fmt.Errorf("dimacs line %d: edge before "+
+					"problem line", lineNo)
+			}
+			if len(fields) != 3 {
+				return nil, fmt.Errorf("dimacs line %d: expected \"e U V\"",
+					lineNo)
+			}
+
+			var ends [2]int
+			for k := range ends {
+				u, err := strconv.Atoi(fields[k+1])
+				if err != nil || u < 1 || u > len(g.Vertices) {
+					return nil, fmt.Errorf("dimacs line %d: invalid vertex "+
+						"%q", lineNo, fields[k+1])
+				}
+				ends[k] = u - 1
+			}
+			if ends[0] == ends[1] {
+				return nil, fmt.Errorf("dimacs line %d: self-loop on "+
+					"vertex %d", lineNo, ends[0]+1)
+			}
+			g.AddUndirectedEdge(ends[0], ends[1])
+
+		default:
+			// other line types (e.g., "n" vertex weights) are ignored
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if g == nil {
+		return nil, fmt.Errorf("dimacs: missing problem line")
+	}
+
+	g.Canonicalize()
+
+	return g, nil
+}
+
+// DumpDIMACS writes a graph to file in the DIMACS .col format; each
+// undirected edge is written once (even if only one of its endpoints lists
+// the other), with 1-based vertex indices. Self-loops are dropped, since
+// LoadDIMACS rejects them
+func (g *Graph) DumpDIMACS(writer io.Writer) error {
+	edges := make([][2]int, 0)
+	for _, edge := range g.undirectedEdges() {
+		if edge[0] != edge[1] {
+			edges = append(edges, edge)
+		}
+	}
+
+	_, err := fmt.Fprintf(writer, "p edge %d %d\n", len(g.Vertices),
+		len(edges))
+	if err != nil {
+		return err
+	}
+
+	for _, edge := range edges {
+		_, err = fmt.Fprintf(writer, "e %d %d\n", edge[0]+1, edge[1]+1)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
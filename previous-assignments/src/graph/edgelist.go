@@ -0,0 +1,211 @@
+package graph
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// IDMap maps the dense vertex indices of a Graph to the original vertex ids
+// of the dataset it was loaded from, i.e., vertex i had id IDMap[i]
+type IDMap []int64
+
+// LoadEdgeList streams a graph in the whitespace-separated edge list format
+// used by SNAP and similar datasets: each line is "U V", optionally followed
+// by other fields (e.g., weights or timestamps) which are ignored, and lines
+// starting with "#" or "%" are comments. Vertex ids may be sparse and
+// arbitrarily large; they are compacted into 0..n-1 in order of first
+// appearance, and the original ids are returned as an IDMap. Edges are
+// symmetrized, and duplicate edges and self-loops are dropped
+func LoadEdgeList(reader io.Reader) (*Graph, IDMap, error) {
+	scanner := bufio.NewScanner(reader)
+	g := New(0)
+	ids := make(IDMap, 0)
+	indices := make(map[int64]int)
+
+	// compact returns the dense index of an original id
+	compact := func(id int64) int {
+		index, ok := indices[id]
+		if !ok {
+			index = len(ids)
+			indices[id] = index
+			ids = append(ids, id)
+			g.AddNode(0)
+		}
+		return index
+	}
+
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") || strings.HasPrefix(line, "%") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 2 {
+			return nil, nil, fmt.Errorf("edgelist line %d: expected \"U V\"",
+				lineNo)
+		}
+
+		var ends [2]int
+		for k := range ends {
+			id, err := strconv.ParseInt(fields[k], 10, 64)
+			if err != nil {
+				return nil, nil, fmt.Errorf("edgelist line %d: invalid "+
+					"vertex id %q", lineNo, fields[k])
+			}
+			ends[k] = compact(id)
+		}
+		if ends[0] != ends[1] {
+			g.AddUndirectedEdge(ends[0], ends[1])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	g.Canonicalize()
+
+	return &g, ids, nil
+}
+
+// LoadMatrixMarket streams a graph from a Matrix Market coordinate file: the
+// "%%MatrixMarket matrix coordinate ..." banner, comment lines starting with
+// "%", a "ROWS COLS NNZ" size line and then one "I J [VALUE...]" entry per
+// line with 1-based indices. The matrix must be square, and is treated as an
+// adjacency matrix: entries are symmetrized, duplicates and diagonal entries
+// are dropped and values are ignored. Matrix Market indices are already
+// dense, so the returned IDMap simply maps vertex i to id i+1
+func LoadMatrixMarket(reader io.Reader) (*Graph, IDMap, error) {
+	scanner := bufio.NewScanner(reader)
+
+	// check banner
+	if !scanner.Scan() {
+		return nil, nil, fmt.Errorf("mtx: missing banner")
+	}
+	banner := strings.Fields(strings.ToLower(scanner.Text()))
+	if len(banner) < 3 || banner[0] != "%%matrixmarket" ||
+		banner[1] != "matrix" || banner[2] != "coordinate" {
+
+		return nil, nil, fmt.Errorf("mtx line 1: expected " +
+			"\"%%%%MatrixMarket matrix coordinate\" banner")
+	}
+
+	var g *Graph
+	for lineNo := 2; scanner.Scan(); lineNo++ {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "%") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		ints := make([]int, 2)
+		for k := range ints {
+			if k >= len(fields) {
+				return nil, nil, fmt.Errorf("mtx line %d: too few fields",
+					lineNo)
+			}
+			value, err := strconv.Atoi(fields[k])
+			if err != nil || value < 0 {
+				return nil, nil, fmt.Errorf("mtx line %d: invalid "+
+					"integer %q", lineNo, fields[k])
+			}
+			ints[k] = value
+		}
+
+		// first non-comment line is the size line
+		if g == nil {
+			if ints[0] != ints[1] {
+				return nil, nil, fmt.Errorf("mtx line %d: matrix is not "+
+					"square (%dx%d)", lineNo, ints[0], ints[1])
+			}
+			newGraph := New(ints[0])
+			g = &newGraph
+			continue
+		}
+
+		i, j := ints[0], ints[1]
+		if i < 1 || i > len(g.Vertices) || j < 1 || j > len(g.Vertices) {
+			return nil, nil, fmt.Errorf("mtx line %d: index out of range",
+				lineNo)
+		}
+		if i != j {
+			g.AddUndirectedEdge(i-1, j-1)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	if g == nil {
+		return nil, nil, fmt.Errorf("mtx: missing size line")
+	}
+
+	g.Canonicalize()
+
+	ids := make(IDMap, len(g.Vertices))
+	for i := range ids {
+		ids[i] = int64(i + 1)
+	}
+
+	return g, ids, nil
+}
+
+// Dump writes an IDMap to file, one original id per line in vertex order
+func (ids IDMap) Dump(writer io.Writer) error {
+	for _, id := range ids {
+		_, err := io.WriteString(writer, strconv.FormatInt(id, 10)+"\n")
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadIDMap reads an IDMap written by IDMap.Dump
+func LoadIDMap(reader io.Reader) (IDMap, error) {
+	scanner := bufio.NewScanner(reader)
+	ids := make(IDMap, 0)
+
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		id, err := strconv.ParseInt(strings.TrimSpace(scanner.Text()), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("idmap line %d: invalid id %q", lineNo,
+				scanner.Text())
+		}
+		ids = append(ids, id)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// DumpColoring writes a graph's coloring to file as "ID COLOR" lines, using
+// the original vertex ids from ids, or the vertex indices if ids is nil
+func (g *Graph) DumpColoring(writer io.Writer, ids IDMap) error {
+	if ids != nil && len(ids) != len(g.Vertices) {
+		return fmt.Errorf("graph: IDMap has %d ids for %d vertices",
+			len(ids), len(g.Vertices))
+	}
+
+	for i := range g.Vertices {
+		id := int64(i)
+		if ids != nil {
+			id = ids[i]
+		}
+		_, err := fmt.Fprintf(writer, "%d %d\n", id, g.Vertices[i].Value)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,181 @@
+package graph
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+)
+
+// uncoloredFill is the fill color for vertices with a negative Value
+const uncoloredFill = "#ffffff"
+
+// conflictColor is the color of edges whose endpoints share a color
+const conflictColor = "#ff0000"
+
+// Palette returns nColors distinct fill colors as "#rrggbb" strings; hues
+// are spaced by the golden angle so that neighboring color indices are
+// easy to tell apart
+func Palette(nColors int) []string {
+	palette := make([]string, nColors)
+	hue := 0.0
+	for i := range palette {
+		// alternate saturation/value a little for large palettes
+		s, v := 0.55, 0.95
+		if i%2 == 1 {
+			s, v = 0.75, 0.80
+		}
+		r, g, b := hsvToRGB(hue, s, v)
+		palette[i] = fmt.Sprintf("#%02x%02x%02x", r, g, b)
+		hue = math.Mod(hue+137.50776, 360)
+	}
+	return palette
+}
+
+// hsvToRGB converts a hue in [0, 360) and saturation, value in [0, 1] to
+// 8-bit RGB components
+func hsvToRGB(h, s, v float64) (int, int, int) {
+	c := v * s
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := v - c
+
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g, b = c, x, 0
+	case h < 120:
+		r, g, b = x, c, 0
+	case h < 180:
+		r, g, b = 0, c, x
+	case h < 240:
+		r, g, b = 0, x, c
+	case h < 300:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+
+	return int((r + m) * 255), int((g + m) * 255), int((b + m) * 255)
+}
+
+// fillColors returns the fill color of each vertex, based on its Value
+func (g *Graph) fillColors() []string {
+	maxColor := -1
+	for i := range g.Vertices {
+		if g.Vertices[i].Value > maxColor {
+			maxColor = g.Vertices[i].Value
+		}
+	}
+	palette := Palette(maxColor + 1)
+
+	fills := make([]string, len(g.Vertices))
+	for i := range g.Vertices {
+		if value := g.Vertices[i].Value; value >= 0 {
+			fills[i] = palette[value]
+		} else {
+			fills[i] = uncoloredFill
+		}
+	}
+	return fills
+}
+
+// undirectedEdges returns each undirected edge {i, j} of a graph once, as
+// [i, j] with i <= j; an edge is included even if only one of its endpoints
+// lists the other, and duplicate entries are dropped
+func (g *Graph) undirectedEdges() [][2]int {
+	seen := make(map[[2]int]bool)
+	edges := make([][2]int, 0)
+	for i := range g.Vertices {
+		for _, j := range g.Vertices[i].Adj {
+			edge := [2]int{i, j}
+			if i > j {
+				edge = [2]int{j, i}
+			}
+			if !seen[edge] {
+				seen[edge] = true
+				edges = append(edges, edge)
+			}
+		}
+	}
+	return edges
+}
+
+// DumpDOT writes a colored graph to file in the Graphviz DOT format: each
+// vertex is filled with its color from Palette and has its Value as the
+// "colorindex" attribute, and edges that violate the coloring (as
+// CheckValidColoring would find them) are drawn thick and red
+func (g *Graph) DumpDOT(writer io.Writer) error {
+	w := bufio.NewWriter(writer)
+	fills := g.fillColors()
+
+	fmt.Fprintf(w, "graph G {\n")
+	fmt.Fprintf(w, "\tnode [style=filled];\n")
+	for i := range g.Vertices {
+		fmt.Fprintf(w, "\t%d [label=\"%d: %d\", colorindex=%d, "+
+			"fillcolor=\"%s\"];\n", i, i, g.Vertices[i].Value,
+			g.Vertices[i].Value, fills[i])
+	}
+
+	// write each undirected edge once
+	for _, edge := range g.undirectedEdges() {
+		i, j := edge[0], edge[1]
+		if g.Vertices[i].Value == g.Vertices[j].Value {
+			fmt.Fprintf(w, "\t%d -- %d [color=\"%s\", penwidth=3];\n",
+				i, j, conflictColor)
+		} else {
+			fmt.Fprintf(w, "\t%d -- %d;\n", i, j)
+		}
+	}
+	fmt.Fprintf(w, "}\n")
+
+	return w.Flush()
+}
+
+// DumpGraphML writes a colored graph to file in the GraphML format, with
+// the same information as DumpDOT: each vertex has its Value as the "color"
+// attribute and its palette color as the "fill" attribute, and each edge
+// has a "conflict" attribute that is true if it violates the coloring
+func (g *Graph) DumpGraphML(writer io.Writer) error {
+	w := bufio.NewWriter(writer)
+	fills := g.fillColors()
+
+	fmt.Fprintf(w, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	fmt.Fprintf(w, "<graphml xmlns=\"http://graphml.graphdrawing.org/"+
+		"xmlns\">\n")
+	fmt.Fprintf(w, "  <key id=\"color\" for=\"node\" attr.name=\"color\" "+
+		"attr.type=\"int\"/>\n")
+	fmt.Fprintf(w, "  <key id=\"fill\" for=\"node\" attr.name=\"fill\" "+
+		"attr.type=\"string\"/>\n")
+	fmt.Fprintf(w, "  <key id=\"conflict\" for=\"edge\" "+
+		"attr.name=\"conflict\" attr.type=\"boolean\">\n")
+	fmt.Fprintf(w, "    <default>false</default>\n")
+	fmt.Fprintf(w, "  </key>\n")
+	fmt.Fprintf(w, "  <graph id=\"G\" edgedefault=\"undirected\">\n")
+
+	for i := range g.Vertices {
+		fmt.Fprintf(w, "    <node id=\"n%d\">\n", i)
+		fmt.Fprintf(w, "      <data key=\"color\">%d</data>\n",
+			g.Vertices[i].Value)
+		fmt.Fprintf(w, "      <data key=\"fill\">%s</data>\n", fills[i])
+		fmt.Fprintf(w, "    </node>\n")
+	}
+
+	// write each undirected edge once
+	for _, edge := range g.undirectedEdges() {
+		i, j := edge[0], edge[1]
+		if g.Vertices[i].Value == g.Vertices[j].Value {
+			fmt.Fprintf(w, "    <edge source=\"n%d\" target=\"n%d\">\n",
+				i, j)
+			fmt.Fprintf(w, "      <data key=\"conflict\">true</data>\n")
+			fmt.Fprintf(w, "    </edge>\n")
+		} else {
+			fmt.Fprintf(w, "    <edge source=\"n%d\" target=\"n%d\"/>\n",
+				i, j)
+		}
+	}
+
+	fmt.Fprintf(w, "  </graph>\n")
+	fmt.Fprintf(w, "</graphml>\n")
+
+	return w.Flush()
+}
@@ -1,14 +1,14 @@
 package graph
 
 import (
+	"math"
 	"math/rand"
 	"sync"
-	"time"
 )
 
 // New returns a new graph
 func New(nVertices int) Graph {
-	return Graph{make([]Vertex, nVertices)}
+	return Graph{Vertices: make([]Vertex, nVertices)}
 }
 
 // AddNode adds a node to a graph
@@ -18,10 +18,24 @@ func (g *Graph) AddNode(value int) {
 		make([]int, 0),
 		sync.Mutex{},
 	})
+
+	// keep attributes parallel to vertices
+	if g.Attrs != nil {
+		if g.Attrs.VertexWeights != nil {
+			g.Attrs.VertexWeights = append(g.Attrs.VertexWeights, 1)
+		}
+		if g.Attrs.EdgeWeights != nil {
+			g.Attrs.EdgeWeights = append(g.Attrs.EdgeWeights, make([]int, 0))
+		}
+		if g.Attrs.VertexLabels != nil {
+			g.Attrs.VertexLabels = append(g.Attrs.VertexLabels, "")
+		}
+	}
 }
 
 // AddUndirectedEdge adds an undirected edge between two nodes in a graph
-// Note that this doesn't check for duplicate edges
+// Note that this doesn't check for duplicate edges (see HasEdge and
+// Canonicalize)
 func (g *Graph) AddUndirectedEdge(n1, n2 int) {
 	if n1 >= len(g.Vertices) || n2 >= len(g.Vertices) {
 		panic("Invalid node indices")
@@ -29,6 +43,35 @@ func (g *Graph) AddUndirectedEdge(n1, n2 int) {
 
 	g.Vertices[n1].Adj = append(g.Vertices[n1].Adj, n2)
 	g.Vertices[n2].Adj = append(g.Vertices[n2].Adj, n1)
+
+	// keep edge weights parallel to adjacency lists
+	if g.Attrs != nil && g.Attrs.EdgeWeights != nil {
+		g.Attrs.EdgeWeights[n1] = append(g.Attrs.EdgeWeights[n1], 1)
+		g.Attrs.EdgeWeights[n2] = append(g.Attrs.EdgeWeights[n2], 1)
+	}
+}
+
+// AddWeightedUndirectedEdge adds an undirected edge with the given weight
+// between two nodes in a graph, allocating edge weights if necessary
+func (g *Graph) AddWeightedUndirectedEdge(n1, n2, weight int) {
+	if g.Attrs == nil || g.Attrs.EdgeWeights == nil {
+		vertexWeights, labels := false, false
+		if g.Attrs != nil {
+			vertexWeights = g.Attrs.VertexWeights != nil
+			labels = g.Attrs.VertexLabels != nil
+		}
+		attrs := g.Attrs
+		g.NewAttributes(vertexWeights, true, labels)
+		if attrs != nil {
+			g.Attrs.VertexWeights = attrs.VertexWeights
+			g.Attrs.VertexLabels = attrs.VertexLabels
+		}
+	}
+
+	g.AddUndirectedEdge(n1, n2)
+	adj1, adj2 := g.Attrs.EdgeWeights[n1], g.Attrs.EdgeWeights[n2]
+	adj1[len(adj1)-1] = weight
+	adj2[len(adj2)-1] = weight
 }
 
 // NewCompleteGraph generates a complete graph with nVertices nodes
@@ -60,28 +103,99 @@ func NewRingGraph(nVertices int) Graph {
 	return g
 }
 
+// newRand returns the random number generator for one stream (e.g., one
+// vertex) of a seeded generator. Streams are derived from the seed and the
+// stream index only, so each one produces the same numbers no matter which
+// thread consumes it, and in what order
+func newRand(seed, stream uint64) *rand.Rand {
+	return rand.New(rand.NewSource(int64(splitmix64(seed ^
+		splitmix64(stream)))))
+}
+
+// sampleLowerEdges appends the edges from vertex i to lower vertices of a
+// random graph with edge probability pEdge. Rather than drawing a Bernoulli
+// trial for each j < i, it uses geometric skip sampling (Batagelj and
+// Brandes, "Efficient generation of large random networks"): the number of
+// failures before the next success is drawn directly, so the work is
+// proportional to the number of edges. Vertex i draws from its own stream
+// (see hashFloat64), so edges don't depend on which thread generates them.
+// pEdge is clamped to [0, 1], e.g., a degree of at least nVertices-1 gives a
+// complete graph
+func sampleLowerEdges(edges [][2]int, i int, pEdge float64,
+	seed uint64) [][2]int {
+
+	if !(pEdge > 0) {
+		return edges
+	}
+	if pEdge >= 1 {
+		for j := 0; j < i; j++ {
+			edges = append(edges, [2]int{i, j})
+		}
+		return edges
+	}
+
+	logQ := math.Log1p(-pEdge)
+	stream := splitmix64(seed ^ splitmix64(uint64(i)))
+
+	for j, k := -1, uint64(0); ; k++ {
+		skip := math.Log1p(-hashFloat64(stream, k)) / logQ
+		if skip >= float64(i-1-j) {
+			break
+		}
+		j += 1 + int(skip)
+		edges = append(edges, [2]int{i, j})
+	}
+
+	return edges
+}
+
+// addEdgeBuffers adds the edges of each buffer to a graph in order, first
+// sizing every adjacency list so that it is only allocated once
+func (g *Graph) addEdgeBuffers(buffers [][][2]int) {
+	degrees := make([]int, len(g.Vertices))
+	for _, buffer := range buffers {
+		for _, edge := range buffer {
+			degrees[edge[0]]++
+			degrees[edge[1]]++
+		}
+	}
+	for i := range g.Vertices {
+		g.Vertices[i].Adj = make([]int, 0, degrees[i])
+	}
+
+	for _, buffer := range buffers {
+		for _, edge := range buffer {
+			g.AddUndirectedEdge(edge[0], edge[1])
+		}
+	}
+}
+
 // NewRandomGraph generates a graph with nVertices nodes and average
-// branching factor bFactor
-func NewRandomGraph(nVertices int, degree float32) Graph {
+// branching factor bFactor in O(nVertices + nEdges) time (see
+// sampleLowerEdges); the same seed always generates the same graph, which is
+// also the graph generated by NewRandomGraphParallel
+func NewRandomGraph(nVertices int, degree float32, seed uint64) Graph {
 	g := New(nVertices)
 
 	// if branching factor is bFactor, then given n1, n2 nodes in g, then the
 	// probability of an undirected edge is bFactor / (nVertices - 1)
-	pEdge := degree / float32(nVertices-1)
+	pEdge := float64(degree) / float64(nVertices-1)
 
+	edges := make([][2]int, 0)
 	for i := 1; i < nVertices; i++ {
-		for j := 0; j < i; j++ {
-			if rand.Float32() < pEdge {
-				g.AddUndirectedEdge(i, j)
-			}
-		}
+		edges = sampleLowerEdges(edges, i, pEdge, seed)
 	}
+	g.addEdgeBuffers([][][2]int{edges})
 
 	return g
 }
 
-// NewRandomGraphParallel generates a random graph in parallel
-func NewRandomGraphParallel(nVertices int, degree float32,
+// NewRandomGraphParallel generates a random graph in parallel in
+// O(nVertices + nEdges) time. Each thread samples the edges of its share of
+// vertices into its own buffer, without locking, and the buffers are merged
+// in order at the end, so the graph only depends on the seed, not on
+// nThreads, and is the same as NewRandomGraph's
+func NewRandomGraphParallel(nVertices int, degree float32, seed uint64,
 	nThreads int) Graph {
 
 	//g := NewParallel(nVertices, nThreads)
@@ -92,33 +206,345 @@ func NewRandomGraphParallel(nVertices int, degree float32,
 		nodesPerThread++
 	}
 
-	pEdge := degree / float32(nVertices-1)
+	pEdge := float64(degree) / float64(nVertices-1)
 
 	var wg sync.WaitGroup
 	wg.Add(nThreads)
 
-	threadFunc := func(start int) {
+	buffers := make([][][2]int, nThreads)
+	threadFunc := func(thread int) {
 		defer wg.Done()
+		start := thread * nodesPerThread
+		edges := make([][2]int, 0)
 		for i := start; i < start+nodesPerThread && i < nVertices; i++ {
-			source := rand.NewSource(time.Now().UnixNano())
-			generator := rand.New(source)
-
-			for j := 0; j < i; j++ {
-				if generator.Float32() < pEdge {
-					g.Vertices[i].Mutex.Lock()
-					g.Vertices[j].Mutex.Lock()
-					g.AddUndirectedEdge(i, j)
-					g.Vertices[j].Mutex.Unlock()
-					g.Vertices[i].Mutex.Unlock()
-				}
-			}
+			edges = sampleLowerEdges(edges, i, pEdge, seed)
 		}
+		buffers[thread] = edges
 	}
 
 	for i := 0; i < nThreads; i++ {
-		go threadFunc(i * nodesPerThread)
+		go threadFunc(i)
 	}
 	wg.Wait()
 
+	// vertices are in increasing order across buffers, so adjacency lists
+	// come out sorted, as with NewRandomGraph
+	g.addEdgeBuffers(buffers)
+
 	return g
 }
+
+// The generators below produce structured graphs whose chromatic number is
+// known, so that colorers can be checked for quality and not just validity;
+// each returns the graph along with its chromatic number
+
+// latticeIndex returns the vertex index of a point in a lattice with the
+// given dimensions, in row-major order
+func latticeIndex(dims, point []int) int {
+	index := 0
+	for d := range dims {
+		index = index*dims[d] + point[d]
+	}
+	return index
+}
+
+// newLatticeGraph generates a grid (or a torus, if wrap is set) with the
+// given dimensions, and returns its chromatic number: a grid is bipartite,
+// and a torus is too unless it wraps around an odd cycle
+func newLatticeGraph(dims []int, wrap bool) (Graph, int) {
+	nVertices := 1
+	for _, dim := range dims {
+		if dim < 1 {
+			panic("Invalid lattice dimensions")
+		}
+		nVertices *= dim
+	}
+	g := New(nVertices)
+
+	point := make([]int, len(dims))
+	neighbor := make([]int, len(dims))
+	hasEdges, hasOddCycle := false, false
+	for i := 0; i < nVertices; i++ {
+		// connect to next vertex along each dimension
+		for d, dim := range dims {
+			copy(neighbor, point)
+			if point[d]+1 < dim {
+				neighbor[d] = point[d] + 1
+			} else if wrap && dim > 2 {
+				// a dimension of size 2 already has its only edge
+				neighbor[d] = 0
+				hasOddCycle = hasOddCycle || dim%2 == 1
+			} else {
+				continue
+			}
+			g.AddUndirectedEdge(i, latticeIndex(dims, neighbor))
+			hasEdges = true
+		}
+
+		// increment point in row-major order
+		for d := len(dims) - 1; d >= 0; d-- {
+			point[d]++
+			if point[d] < dims[d] {
+				break
+			}
+			point[d] = 0
+		}
+	}
+
+	switch {
+	case hasOddCycle:
+		return g, 3
+	case hasEdges:
+		return g, 2
+	default:
+		return g, 1
+	}
+}
+
+// NewGridGraph generates a rows x cols 2D grid graph (chromatic number 2)
+func NewGridGraph(rows, cols int) (Graph, int) {
+	return newLatticeGraph([]int{rows, cols}, false)
+}
+
+// NewGrid3DGraph generates an x * y * z 3D grid graph (chromatic number 2)
+func NewGrid3DGraph(x, y, z int) (Graph, int) {
+	return newLatticeGraph([]int{x, y, z}, false)
+}
+
+// NewTorusGraph generates a rows x cols 2D torus, i.e., a grid that wraps
+// around (chromatic number 3 if either dimension is odd, else 2)
+func NewTorusGraph(rows, cols int) (Graph, int) {
+	return newLatticeGraph([]int{rows, cols}, true)
+}
+
+// NewTorus3DGraph generates an x * y * z 3D torus (chromatic number 3 if any
+// dimension is odd, else 2)
+func NewTorus3DGraph(x, y, z int) (Graph, int) {
+	return newLatticeGraph([]int{x, y, z}, true)
+}
+
+// NewHypercubeGraph generates the d-dimensional hypercube, in which the
+// 2^d vertices are adjacent if their indices differ in exactly one bit
+// (chromatic number 2)
+func NewHypercubeGraph(d int) (Graph, int) {
+	if d < 0 {
+		panic("Invalid hypercube dimension")
+	}
+
+	nVertices := 1 << d
+	g := New(nVertices)
+	for i := 0; i < nVertices; i++ {
+		for bit := 0; bit < d; bit++ {
+			if j := i ^ (1 << bit); j > i {
+				g.AddUndirectedEdge(i, j)
+			}
+		}
+	}
+
+	if d == 0 {
+		return g, 1
+	}
+	return g, 2
+}
+
+// NewCompleteBipartiteGraph generates the complete bipartite graph K_{m,n}:
+// vertices 0..m-1 are adjacent to all of vertices m..m+n-1 (chromatic
+// number 2)
+func NewCompleteBipartiteGraph(m, n int) (Graph, int) {
+	g := New(m + n)
+	for i := 0; i < m; i++ {
+		for j := m; j < m+n; j++ {
+			g.AddUndirectedEdge(i, j)
+		}
+	}
+
+	switch {
+	case m > 0 && n > 0:
+		return g, 2
+	case m+n > 0:
+		return g, 1
+	default:
+		return g, 0
+	}
+}
+
+// NewMycielskiGraph generates the k-th Mycielski graph M_k, which is
+// triangle-free with chromatic number k: M_1 is a single vertex, M_2 is K_2
+// and M_{k+1} is the Mycielskian of M_k, e.g., M_3 is the 5-cycle and M_4
+// is the Groetzsch graph (myciel3 in the DIMACS benchmarks)
+func NewMycielskiGraph(k int) (Graph, int) {
+	if k < 1 {
+		panic("Invalid Mycielski graph index")
+	}
+
+	g := New(1)
+	if k >= 2 {
+		g = New(2)
+		g.AddUndirectedEdge(0, 1)
+	}
+
+	for i := 2; i < k; i++ {
+		// the Mycielskian of a graph on n vertices adds a "shadow" vertex
+		// n+v for each vertex v, adjacent to v's neighbors, and a vertex 2n
+		// adjacent to all shadows
+		n := len(g.Vertices)
+		for v := 0; v <= n; v++ {
+			g.AddNode(0)
+		}
+		for v := 0; v < n; v++ {
+			for _, u := range g.Vertices[v].Adj {
+				if u < n {
+					g.AddUndirectedEdge(n+v, u)
+				}
+			}
+			g.AddUndirectedEdge(n+v, 2*n)
+		}
+	}
+
+	return g, k
+}
+
+// NewRandomRegularBipartiteGraph generates a random k-regular bipartite
+// graph with nPerSide vertices on each side, as the union of k random
+// perfect matchings with no repeated edges (chromatic number 2); the same
+// seed always generates the same graph
+func NewRandomRegularBipartiteGraph(nPerSide, k int,
+	seed uint64) (Graph, int) {
+	if k < 0 || k > nPerSide {
+		panic("Invalid regular bipartite graph parameters")
+	}
+
+	g := New(2 * nPerSide)
+	generator := newRand(seed, 0)
+
+	// each matching is a random permutation of the right side; retry
+	// permutations that would repeat an edge
+	adjacent := make(map[[2]int]bool)
+	for matching := 0; matching < k; {
+		perm := generator.Perm(nPerSide)
+		repeated := false
+		for i, j := range perm {
+			if adjacent[[2]int{i, j}] {
+				repeated = true
+				break
+			}
+		}
+		if repeated {
+			continue
+		}
+
+		for i, j := range perm {
+			adjacent[[2]int{i, j}] = true
+			g.AddUndirectedEdge(i, nPerSide+j)
+		}
+		matching++
+	}
+
+	if k == 0 {
+		return g, 1
+	}
+	return g, 2
+}
+
+// NewRandomRegularGraph generates a random simple k-regular graph by
+// pairing edge "stubs" at random, only pairing stubs that would form a
+// valid edge and restarting if that becomes impossible (Steger and Wormald,
+// "Generating random regular graphs quickly"). The same seed always
+// generates the same graph. Unlike the generators above, its chromatic
+// number isn't known in general, so it returns the best known bound (see
+// regularChromaticBound), which is exact for k <= 2
+func NewRandomRegularGraph(nVertices, k int, seed uint64) (Graph, int) {
+	if k < 0 || k >= nVertices || nVertices*k%2 != 0 {
+		panic("Invalid regular graph parameters")
+	}
+
+	generator := newRand(seed, 0)
+	for {
+		g := New(nVertices)
+		adjacent := make(map[[2]int]bool)
+		stubs := make([]int, 0, nVertices*k)
+		for i := 0; i < nVertices; i++ {
+			for j := 0; j < k; j++ {
+				stubs = append(stubs, i)
+			}
+		}
+
+		// pair random stubs until none are left or no valid pair remains
+		for len(stubs) > 0 {
+			found := false
+			for attempt := 0; attempt < 10*len(stubs); attempt++ {
+				a, b := generator.Intn(len(stubs)),
+					generator.Intn(len(stubs))
+				u, v := stubs[a], stubs[b]
+				if u == v || adjacent[[2]int{u, v}] {
+					continue
+				}
+
+				adjacent[[2]int{u, v}] = true
+				adjacent[[2]int{v, u}] = true
+				g.AddUndirectedEdge(u, v)
+
+				// remove both stubs by swapping with the end
+				if a < b {
+					a, b = b, a
+				}
+				stubs[a] = stubs[len(stubs)-1]
+				stubs = stubs[:len(stubs)-1]
+				stubs[b] = stubs[len(stubs)-1]
+				stubs = stubs[:len(stubs)-1]
+				found = true
+				break
+			}
+			if !found {
+				break
+			}
+		}
+
+		if len(stubs) == 0 {
+			return g, regularChromaticBound(&g, k)
+		}
+	}
+}
+
+// regularChromaticBound returns an upper bound on the chromatic number of a
+// k-regular graph from the sizes of its connected components. For k <= 2 it
+// is exact: a 2-regular graph is a union of cycles, which needs a third
+// color only for an odd cycle. For k >= 3 it's Brooks' theorem: k colors
+// suffice unless a component is complete, i.e., has k+1 vertices
+func regularChromaticBound(g *Graph, k int) int {
+	switch k {
+	case 0:
+		return 1
+	case 1:
+		return 2
+	}
+
+	visited := make([]bool, len(g.Vertices))
+	stack := make([]int, 0)
+	for i := range g.Vertices {
+		if visited[i] {
+			continue
+		}
+
+		// find the size of the component of vertex i
+		size := 0
+		visited[i] = true
+		stack = append(stack[:0], i)
+		for len(stack) > 0 {
+			u := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			size++
+			for _, v := range g.Vertices[u].Adj {
+				if !visited[v] {
+					visited[v] = true
+					stack = append(stack, v)
+				}
+			}
+		}
+
+		if (k == 2 && size%2 == 1) || (k > 2 && size == k+1) {
+			return k + 1
+		}
+	}
+	return k
+}
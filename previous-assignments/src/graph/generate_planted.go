@@ -0,0 +1,51 @@
+package graph
+
+// NewPlantedGraph generates a random graph that is guaranteed to be
+// k-colorable: the vertices are randomly split into k parts of (almost)
+// equal size, and each pair of vertices in different parts is connected with
+// a fixed probability, chosen so that the average degree is degree. The
+// hidden partition is returned as well, as a valid coloring that uses at most
+// k colors; this gives an upper bound on the chromatic number to judge the
+// colorers against, even for graphs too large to color exactly. Generation
+// takes O(nVertices + nEdges) time (see sampleLowerEdges), and the same seed
+// always generates the same graph
+func NewPlantedGraph(nVertices, k int, degree float32,
+	seed uint64) (Graph, []int) {
+
+	if k < 1 || nVertices < k {
+		panic("Invalid planted graph parameters")
+	}
+
+	g := New(nVertices)
+	generator := newRand(seed, 0)
+
+	// assign parts as a random permutation of vertices, so that part sizes
+	// differ by at most one
+	coloring := generator.Perm(nVertices)
+	for i := range coloring {
+		coloring[i] %= k
+	}
+
+	// a vertex can only be adjacent to the (about) nVertices*(k-1)/k vertices
+	// in other parts
+	nCandidates := float64(nVertices) * float64(k-1) / float64(k)
+	pEdge := 0.0
+	if k > 1 {
+		pEdge = float64(degree) / nCandidates
+	}
+
+	// sample edges between all pairs, and drop those within a part
+	edges := make([][2]int, 0)
+	for i := 1; i < nVertices; i++ {
+		edges = sampleLowerEdges(edges, i, pEdge, seed)
+	}
+	crossEdges := edges[:0]
+	for _, edge := range edges {
+		if coloring[edge[0]] != coloring[edge[1]] {
+			crossEdges = append(crossEdges, edge)
+		}
+	}
+	g.addEdgeBuffers([][][2]int{crossEdges})
+
+	return g, coloring
+}
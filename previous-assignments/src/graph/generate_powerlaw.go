@@ -0,0 +1,205 @@
+package graph
+
+import (
+	"math"
+	"sync"
+)
+
+// NewBarabasiAlbertGraph generates a scale-free graph by preferential
+// attachment: starting from a complete graph on m+1 vertices, each new
+// vertex is connected to m distinct existing vertices, chosen with
+// probability proportional to their degree. The same seed always generates
+// the same graph
+func NewBarabasiAlbertGraph(nVertices, m int, seed uint64) Graph {
+	if m < 1 || nVertices <= m {
+		panic("Invalid Barabasi-Albert parameters")
+	}
+
+	g := NewCompleteGraph(m + 1)
+	generator := newRand(seed, 0)
+	for i := m + 1; i < nVertices; i++ {
+		g.AddNode(0)
+	}
+
+	// every edge adds both of its endpoints to this list, so sampling a
+	// uniform element samples a vertex proportional to its degree
+	endpoints := make([]int, 0, 2*m*nVertices)
+	for i := 0; i <= m; i++ {
+		for range g.Vertices[i].Adj {
+			endpoints = append(endpoints, i)
+		}
+	}
+
+	// targets are kept in the order they were chosen (rather than in map
+	// order), so that the same seed always generates the same graph
+	targets := make([]int, 0, m)
+	isTarget := make(map[int]bool, m)
+	for i := m + 1; i < nVertices; i++ {
+		// choose m distinct targets among existing vertices
+		targets = targets[:0]
+		for k := range isTarget {
+			delete(isTarget, k)
+		}
+		for len(targets) < m {
+			j := endpoints[generator.Intn(len(endpoints))]
+			if !isTarget[j] {
+				isTarget[j] = true
+				targets = append(targets, j)
+			}
+		}
+
+		for _, j := range targets {
+			g.AddUndirectedEdge(i, j)
+			endpoints = append(endpoints, i, j)
+		}
+	}
+
+	return g
+}
+
+// splitmix64 is a fast, high-quality hash of a 64-bit integer, used to
+// generate random numbers that depend only on their index, so that they can
+// be generated in any order by any number of threads
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// NewBarabasiAlbertGraphParallel generates a preferential attachment graph
+// in parallel, using the algorithm from Sanders and Schulz, "Scalable
+// generation of scale-free graphs" (https://arxiv.org/abs/1602.07106).
+// Vertex i >= 1 adds m edges (vertex 1 to vertex 0); the endpoints of edge
+// e are stored at positions 2e (the source, known in advance) and 2e+1 (the
+// target) of a virtual endpoint list, and the target is a copy of a
+// uniformly random earlier position, which can be resolved independently of
+// all other edges.
+// Unlike NewBarabasiAlbertGraph, repeated targets and self-loops are
+// dropped, so some vertices may have slightly fewer than m edges. The graph
+// only depends on the seed, not on nThreads
+func NewBarabasiAlbertGraphParallel(nVertices, m int, seed uint64,
+	nThreads int) Graph {
+	if m < 1 || nVertices <= m {
+		panic("Invalid Barabasi-Albert parameters")
+	}
+
+	g := New(nVertices)
+	nEdges := (nVertices - 1) * m
+
+	// resolve returns the vertex at a position of the endpoint list
+	resolve := func(position int) int {
+		for position%2 == 1 {
+			// vertex 1 can only attach to vertex 0
+			e := position / 2
+			if e < m {
+				return 0
+			}
+
+			// target of edge e copies a random position in [0, 2e)
+			position = int(splitmix64(seed^uint64(position)) %
+				uint64(2*e))
+		}
+		return 1 + position/2/m
+	}
+
+	edgesPerThread := nEdges / nThreads
+	if nEdges%nThreads != 0 {
+		edgesPerThread++
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(nThreads)
+
+	buffers := make([][][2]int, nThreads)
+	threadFunc := func(thread int) {
+		defer wg.Done()
+		start := thread * edgesPerThread
+		edges := make([][2]int, 0)
+		for e := start; e < start+edgesPerThread && e < nEdges; e++ {
+			i, j := resolve(2*e), resolve(2*e+1)
+			if i != j {
+				edges = append(edges, [2]int{i, j})
+			}
+		}
+		buffers[thread] = edges
+	}
+
+	for i := 0; i < nThreads; i++ {
+		go threadFunc(i)
+	}
+	wg.Wait()
+
+	// repeated targets are only dropped once all edges are added
+	g.addEdgeBuffers(buffers)
+	g.Canonicalize()
+
+	return g
+}
+
+// PowerLawDegrees samples nVertices degrees from a discrete power law
+// P(d) ~ d^-exponent on [minDegree, maxDegree], e.g., for use with
+// NewConfigurationGraph. The sum of the degrees is made even by
+// incrementing one degree if necessary. The same seed always samples the
+// same degrees
+func PowerLawDegrees(nVertices int, exponent float64,
+	minDegree, maxDegree int, seed uint64) []int {
+
+	if minDegree < 1 || maxDegree < minDegree || exponent <= 1 {
+		panic("Invalid power law parameters")
+	}
+
+	// inverse transform sampling of the continuous power law, rounded down
+	a := math.Pow(float64(minDegree), 1-exponent)
+	b := math.Pow(float64(maxDegree+1), 1-exponent)
+
+	generator := newRand(seed, 0)
+	degrees := make([]int, nVertices)
+	sum := 0
+	for i := range degrees {
+		u := generator.Float64()
+		d := int(math.Pow(a+u*(b-a), 1/(1-exponent)))
+		if d > maxDegree {
+			d = maxDegree
+		}
+		degrees[i] = d
+		sum += d
+	}
+
+	if sum%2 == 1 && nVertices > 0 {
+		degrees[0]++
+	}
+
+	return degrees
+}
+
+// NewConfigurationGraph generates a random graph with (approximately) the
+// given degree sequence using the configuration model: each vertex gets
+// degrees[i] edge "stubs", and the stubs are paired uniformly at random.
+// Self-loops and multi-edges are dropped (the "erased" configuration model),
+// so high-degree vertices may end up with slightly lower degree. The same
+// seed always generates the same graph
+func NewConfigurationGraph(degrees []int, seed uint64) Graph {
+	g := New(len(degrees))
+
+	stubs := make([]int, 0)
+	for i, d := range degrees {
+		for k := 0; k < d; k++ {
+			stubs = append(stubs, i)
+		}
+	}
+	newRand(seed, 0).Shuffle(len(stubs), func(i, j int) {
+		stubs[i], stubs[j] = stubs[j], stubs[i]
+	})
+
+	// pair consecutive stubs; an odd stub out is dropped
+	for k := 0; k+1 < len(stubs); k += 2 {
+		if stubs[k] != stubs[k+1] {
+			g.AddUndirectedEdge(stubs[k], stubs[k+1])
+		}
+	}
+
+	g.Canonicalize()
+
+	return g
+}
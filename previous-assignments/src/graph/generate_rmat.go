@@ -0,0 +1,211 @@
+package graph
+
+import (
+	"io"
+	"math/rand"
+	"strconv"
+	"sync"
+)
+
+// RMATParams are the parameters of the R-MAT (recursive matrix) generator:
+// each edge is placed by recursively choosing one of the four quadrants of
+// the adjacency matrix with probabilities A, B, C and D = 1-A-B-C
+type RMATParams struct {
+	Scale      int     // number of vertices is 2^Scale
+	EdgeFactor int     // number of generated edges is EdgeFactor * 2^Scale
+	A, B, C    float64 // quadrant probabilities
+	Seed       uint64  // the same seed always generates the same graph
+}
+
+// rmatBlockSize is the number of edges generated at once by each thread of
+// WriteRMATEdgeList
+const rmatBlockSize = 1 << 16
+
+// Graph500RMATParams returns the R-MAT parameters used by the Graph500
+// benchmark (https://graph500.org) for a given scale
+func Graph500RMATParams(scale int, seed uint64) RMATParams {
+	return RMATParams{
+		Scale:      scale,
+		EdgeFactor: 16,
+		A:          0.57,
+		B:          0.19,
+		C:          0.19,
+		Seed:       seed,
+	}
+}
+
+// check panics if the parameters are invalid, like the other generators
+func (params *RMATParams) check() {
+	if params.Scale < 1 || params.Scale > 40 || params.EdgeFactor < 1 ||
+		params.A < 0 || params.B < 0 || params.C < 0 ||
+		params.A+params.B+params.C > 1 {
+
+		panic("Invalid R-MAT parameters")
+	}
+}
+
+// hashFloat64 returns a uniform random float64 in [0, 1) that only depends
+// on the seed and index (see splitmix64)
+func hashFloat64(seed, index uint64) float64 {
+	return float64(splitmix64(seed+splitmix64(index))>>11) / (1 << 53)
+}
+
+// rmatGenerator generates the edges of an R-MAT graph independently of each
+// other, so that any number of threads produces the same edges
+type rmatGenerator struct {
+	params RMATParams
+	perm   []int
+}
+
+// newRMATGenerator sets up an R-MAT generator; as in Graph500, vertex
+// indices are randomly permuted so that high-degree vertices aren't all
+// clustered at low indices
+func newRMATGenerator(params RMATParams) *rmatGenerator {
+	params.check()
+	source := rand.NewSource(int64(splitmix64(params.Seed)))
+	return &rmatGenerator{
+		params: params,
+		perm:   rand.New(source).Perm(1 << params.Scale),
+	}
+}
+
+// nEdges returns the number of edges to generate (before dropping
+// self-loops and duplicates)
+func (gen *rmatGenerator) nEdges() int {
+	return gen.params.EdgeFactor << gen.params.Scale
+}
+
+// edge returns the endpoints of edge e
+func (gen *rmatGenerator) edge(e int) (int, int) {
+	params := &gen.params
+	ab, abc := params.A+params.B, params.A+params.B+params.C
+
+	u, v := 0, 0
+	for level := 0; level < params.Scale; level++ {
+		r := hashFloat64(params.Seed,
+			uint64(e)*uint64(params.Scale)+uint64(level))
+		u <<= 1
+		v <<= 1
+		switch {
+		case r < params.A:
+		case r < ab:
+			v |= 1
+		case r < abc:
+			u |= 1
+		default:
+			u |= 1
+			v |= 1
+		}
+	}
+
+	return gen.perm[u], gen.perm[v]
+}
+
+// generateBlock generates the edges in [start, end), skipping self-loops
+func (gen *rmatGenerator) generateBlock(start, end int) [][2]int {
+	edges := make([][2]int, 0, end-start)
+	for e := start; e < end; e++ {
+		u, v := gen.edge(e)
+		if u != v {
+			edges = append(edges, [2]int{u, v})
+		}
+	}
+	return edges
+}
+
+// NewRMATGraph generates an R-MAT graph in parallel. Each thread generates
+// its share of edges into its own buffer, without locking, and the buffers
+// are merged in order at the end, so the graph only depends on the
+// parameters (including the seed), not on nThreads. Self-loops and
+// duplicate edges are dropped
+func NewRMATGraph(params RMATParams, nThreads int) Graph {
+	gen := newRMATGenerator(params)
+	nEdges := gen.nEdges()
+	g := New(1 << params.Scale)
+
+	edgesPerThread := nEdges / nThreads
+	if nEdges%nThreads != 0 {
+		edgesPerThread++
+	}
+
+	buffers := make([][][2]int, nThreads)
+	var wg sync.WaitGroup
+	wg.Add(nThreads)
+	for i := 0; i < nThreads; i++ {
+		go func(i int) {
+			defer wg.Done()
+			start := i * edgesPerThread
+			end := start + edgesPerThread
+			if start > nEdges {
+				start = nEdges
+			}
+			if end > nEdges {
+				end = nEdges
+			}
+			buffers[i] = gen.generateBlock(start, end)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, buffer := range buffers {
+		for _, edge := range buffer {
+			g.AddUndirectedEdge(edge[0], edge[1])
+		}
+	}
+
+	g.Canonicalize()
+
+	return g
+}
+
+// WriteRMATEdgeList generates an R-MAT graph in parallel and streams it to
+// file as an edge list (one "U V" line per edge, see LoadEdgeList) without
+// keeping the graph in memory. Blocks of edges are generated by nThreads
+// threads at a time and written in order, so the output only depends on the
+// parameters. Self-loops are skipped, but duplicate edges are not (they are
+// dropped by LoadEdgeList)
+func WriteRMATEdgeList(writer io.Writer, params RMATParams,
+	nThreads int) error {
+
+	gen := newRMATGenerator(params)
+	nEdges := gen.nEdges()
+	buffers := make([][]byte, nThreads)
+	var wg sync.WaitGroup
+
+	for start := 0; start < nEdges; start += nThreads * rmatBlockSize {
+		// generate and format the next nThreads blocks in parallel
+		wg.Add(nThreads)
+		for i := 0; i < nThreads; i++ {
+			go func(i int) {
+				defer wg.Done()
+				blockStart := start + i*rmatBlockSize
+				blockEnd := blockStart + rmatBlockSize
+				if blockStart > nEdges {
+					blockStart = nEdges
+				}
+				if blockEnd > nEdges {
+					blockEnd = nEdges
+				}
+
+				buf := buffers[i][:0]
+				for _, edge := range gen.generateBlock(blockStart, blockEnd) {
+					buf = strconv.AppendInt(buf, int64(edge[0]), 10)
+					buf = append(buf, ' ')
+					buf = strconv.AppendInt(buf, int64(edge[1]), 10)
+					buf = append(buf, '\n')
+				}
+				buffers[i] = buf
+			}(i)
+		}
+		wg.Wait()
+
+		// write blocks in order
+		for i := range buffers {
+			if _, err := writer.Write(buffers[i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
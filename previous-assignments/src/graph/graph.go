@@ -17,4 +17,23 @@ type Vertex struct {
 // Graph represents a very simple graph data structure
 type Graph struct {
 	Vertices []Vertex
+	Attrs    *Attributes // optional vertex/edge attributes, nil if none
+}
+
+// Interface is the minimal read/write graph interface needed by the
+// colorers, so that other graph layouts (e.g., CSR or partition-local
+// subgraphs) can be colored without copying them into a Graph. Neighbor
+// indices are whatever the layout stores, e.g., global indices for a
+// distributed subgraph
+type Interface interface {
+	// NumVertices returns the number of vertices
+	NumVertices() int
+	// Degree returns the number of neighbors of vertex i
+	Degree(i int) int
+	// Neighbor returns the k-th neighbor of vertex i, for 0 <= k < Degree(i)
+	Neighbor(i, k int) int
+	// Color returns the color of vertex i
+	Color(i int) int
+	// SetColor sets the color of vertex i
+	SetColor(i, color int)
 }
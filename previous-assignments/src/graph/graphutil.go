@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"io"
 	"strconv"
-	"strings"
 )
 
 // Print prints out a list of a graph's vertices and values, as well as their
@@ -45,53 +44,60 @@ func (g *Graph) CheckValidColoring() bool {
 	return true
 }
 
-// Load reads a graph from file
+// Load reads a graph from file; both the text format written by Dump and the
+// binary format written by DumpBinary are detected automatically, as is gzip
+// or zstd compression (see Decompress). Malformed
+// lines are reported as errors, but the graph structure is not validated
+// (see LoadStrict)
 func Load(reader io.Reader) (*Graph, error) {
-	scanner := bufio.NewScanner(reader)
+	bufReader, err := Decompress(reader)
+	if err != nil {
+		return nil, err
+	}
+	if IsBinary(bufReader) {
+		return LoadBinary(bufReader)
+	}
+
+	scanner := bufio.NewScanner(bufReader)
 
 	// get number of vertices and create graph
-	var nVertices int
 	scanner.Scan()
-	nVertices, err := strconv.Atoi(scanner.Text())
+	nVertices, flags, err := ParseTextHeader(scanner.Text())
 	if err != nil {
 		return nil, err
 	}
-	g := Graph{make([]Vertex, nVertices)}
+	g := Graph{Vertices: make([]Vertex, nVertices)}
+	g.newAttrsFromFlags(flags)
 
 	// read in all vertices
 	for i := 0; scanner.Scan(); i++ {
-		v := &g.Vertices[i]
-
-		// split into value and adj list
-		vertexComponents := strings.Split(scanner.Text(), ";")
+		if i >= nVertices {
+			return nil, &ValidationError{ERR_COUNT_MISMATCH, vertexLine(i),
+				fmt.Sprintf("more than %d vertices", nVertices)}
+		}
 
-		// get vertex value
-		value, err := strconv.Atoi(vertexComponents[0])
+		value, adj, attrs, err := parseTextLine(scanner.Text(),
+			vertexLine(i), flags)
 		if err != nil {
 			return nil, err
 		}
-		v.Value = value
-
-		// get vertex adjacency list, or skip if no adjacent vertices
-		if len(vertexComponents[1]) == 0 {
-			continue
-		}
-		for _, adj := range strings.Split(vertexComponents[1], ",") {
-			adjInt, err := strconv.Atoi(adj)
+		g.Vertices[i].Value = value
+		g.Vertices[i].Adj = adj
+		if g.Attrs != nil {
+			err = g.setTextAttrs(i, attrs, flags, vertexLine(i))
 			if err != nil {
 				return nil, err
 			}
-			v.Adj = append(v.Adj, adjInt)
 		}
 	}
 
 	return &g, nil
 }
 
-// Dump writes a graph to file
+// Dump writes a graph to file, including its attributes if it has any
 func (g *Graph) Dump(writer io.Writer) error {
-	// write number of vertices
-	_, err := io.WriteString(writer, strconv.Itoa(len(g.Vertices))+"\n")
+	// write number of vertices and attribute flags
+	_, err := io.WriteString(writer, g.textHeader()+"\n")
 	if err != nil {
 		return err
 	}
@@ -106,6 +112,7 @@ func (g *Graph) Dump(writer io.Writer) error {
 			}
 			s += strconv.Itoa(j)
 		}
+		s += g.textAttrs(i)
 		_, err = io.WriteString(writer, s+"\n")
 		if err != nil {
 			return err
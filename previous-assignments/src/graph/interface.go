@@ -0,0 +1,58 @@
+package graph
+
+// NumVertices returns the number of vertices in the graph
+func (g *Graph) NumVertices() int {
+	return len(g.Vertices)
+}
+
+// Degree returns the number of neighbors of vertex i
+func (g *Graph) Degree(i int) int {
+	return len(g.Vertices[i].Adj)
+}
+
+// Neighbor returns the k-th neighbor of vertex i
+func (g *Graph) Neighbor(i, k int) int {
+	return g.Vertices[i].Adj[k]
+}
+
+// Color returns the color (Value) of vertex i
+func (g *Graph) Color(i int) int {
+	return g.Vertices[i].Value
+}
+
+// SetColor sets the color (Value) of vertex i
+func (g *Graph) SetColor(i, color int) {
+	g.Vertices[i].Value = color
+}
+
+// Neighbor returns the k-th neighbor of vertex i
+func (c *CSR) Neighbor(i, k int) int {
+	return int(c.Adj[int(c.Offsets[i])+k])
+}
+
+// Color returns the color of vertex i
+func (c *CSR) Color(i int) int {
+	return int(c.Colors[i])
+}
+
+// SetColor sets the color of vertex i
+func (c *CSR) SetColor(i, color int) {
+	c.Colors[i] = int32(color)
+}
+
+// IsValidColoring checks whether any Interface is appropriately
+// colored
+func IsValidColoring(g Interface) bool {
+	for i := 0; i < g.NumVertices(); i++ {
+		for k := 0; k < g.Degree(i); k++ {
+			if g.Color(i) == g.Color(g.Neighbor(i, k)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// static checks that both layouts implement Interface
+var _ Interface = &Graph{}
+var _ Interface = &CSR{}
@@ -0,0 +1,254 @@
+package graph
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// METISWeights holds the optional vertex sizes, vertex weights and edge
+// weights of a graph in the METIS format; each field is nil if absent
+type METISWeights struct {
+	Sizes  []int   // size of each vertex
+	Vertex [][]int // ncon weights of each vertex
+	Edge   [][]int // weight of each edge, parallel to each vertex's Adj
+}
+
+// LoadMETIS reads a graph in the METIS (or Chaco) adjacency format: comment
+// lines start with "%", the header is "N M [FMT [NCON]]", and line i+1
+// lists the 1-based neighbors of vertex i, preceded by the vertex size
+// and/or weights and with each neighbor followed by an edge weight as
+// indicated by FMT. The weights are returned separately, and are nil if FMT
+// indicates an unweighted graph; the first vertex weight and the edge
+// weights are also stored in the graph's Attributes
+func LoadMETIS(reader io.Reader) (*Graph, *METISWeights, error) {
+	scanner := bufio.NewScanner(reader)
+	lineNo := 0
+
+	// nextLine returns the fields of the next non-comment line; blank lines
+	// are meaningful (vertices with no neighbors), so they aren't skipped
+	nextLine := func() ([]string, bool) {
+		for scanner.Scan() {
+			lineNo++
+			if strings.HasPrefix(scanner.Text(), "%") {
+				continue
+			}
+			return strings.Fields(scanner.Text()), true
+		}
+		return nil, false
+	}
+
+	// parse header
+	header, ok := nextLine()
+	if !ok {
+		return nil, nil, fmt.Errorf("metis: missing header")
+	}
+	if len(header) < 2 || len(header) > 4 {
+		return nil, nil, fmt.Errorf("metis line %d: expected \"N M "+
+			"[FMT [NCON]]\"", lineNo)
+	}
+	headerInts := []int{0, 0, 0, 1}
+	for k, field := range header {
+		// FMT is a string of binary digits, e.g., "011"
+		base := 10
+		if k == 2 {
+			base = 2
+		}
+		value, err := strconv.ParseInt(field, base, 0)
+		if err != nil || value < 0 {
+			return nil, nil, fmt.Errorf("metis line %d: invalid header "+
+				"field %q", lineNo, field)
+		}
+		headerInts[k] = int(value)
+	}
+	nVertices, nEdges, format, ncon := headerInts[0], headerInts[1],
+		headerInts[2], headerInts[3]
+	hasSizes, hasVertexWeights, hasEdgeWeights :=
+		format&4 != 0, format&2 != 0, format&1 != 0
+	if !hasVertexWeights {
+		ncon = 0
+	}
+
+	g := New(nVertices)
+	var weights *METISWeights
+	if format != 0 {
+		weights = &METISWeights{}
+		if hasSizes {
+			weights.Sizes = make([]int, nVertices)
+		}
+		if hasVertexWeights {
+			weights.Vertex = make([][]int, nVertices)
+		}
+		if hasEdgeWeights {
+			weights.Edge = make([][]int, nVertices)
+		}
+	}
+
+	// parse one line per vertex
+	nAdj := 0
+	for i := 0; i < nVertices; i++ {
+		fields, ok := nextLine()
+		if !ok {
+			return nil, nil, fmt.Errorf("metis: expected %d vertices, got %d",
+				nVertices, i)
+		}
+
+		ints := make([]int, len(fields))
+		for k, field := range fields {
+			value, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, nil, fmt.Errorf("metis line %d: invalid "+
+					"integer %q", lineNo, field)
+			}
+			ints[k] = value
+		}
+
+		// vertex size and weights precede neighbors
+		nPrefix := ncon
+		if hasSizes {
+			nPrefix++
+		}
+		if len(ints) < nPrefix {
+			return nil, nil, fmt.Errorf("metis line %d: missing vertex "+
+				"weights", lineNo)
+		}
+		if hasSizes {
+			weights.Sizes[i] = ints[0]
+			ints = ints[1:]
+		}
+		if hasVertexWeights {
+			weights.Vertex[i] = ints[:ncon]
+			ints = ints[ncon:]
+		}
+
+		// neighbors, each optionally followed by an edge weight
+		stride := 1
+		if hasEdgeWeights {
+			stride = 2
+		}
+		if len(ints)%stride != 0 {
+			return nil, nil, fmt.Errorf("metis line %d: neighbor without "+
+				"edge weight", lineNo)
+		}
+		v := &g.Vertices[i]
+		for k := 0; k < len(ints); k += stride {
+			j := ints[k]
+			if j < 1 || j > nVertices {
+				return nil, nil, fmt.Errorf("metis line %d: invalid vertex "+
+					"%d", lineNo, j)
+			}
+			v.Adj = append(v.Adj, j-1)
+			if hasEdgeWeights {
+				weights.Edge[i] = append(weights.Edge[i], ints[k+1])
+			}
+		}
+		nAdj += len(v.Adj)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	// each undirected edge is listed by both of its endpoints
+	if nAdj != 2*nEdges {
+		return nil, nil, fmt.Errorf("metis: header declares %d edges, "+
+			"found %d adjacency entries", nEdges, nAdj)
+	}
+
+	// keep weights as graph attributes
+	if hasVertexWeights || hasEdgeWeights {
+		g.NewAttributes(hasVertexWeights && ncon > 0, hasEdgeWeights, false)
+		if hasVertexWeights && ncon > 0 {
+			for i := range g.Vertices {
+				g.Attrs.VertexWeights[i] = weights.Vertex[i][0]
+			}
+		}
+		if hasEdgeWeights {
+			for i := range g.Vertices {
+				g.Attrs.EdgeWeights[i] = append([]int{}, weights.Edge[i]...)
+			}
+		}
+	}
+
+	return &g, weights, nil
+}
+
+// DumpMETIS writes a graph to file in the METIS adjacency format; only the
+// fields of weights that are non-nil are written, and if weights is nil, the
+// graph's own vertex and edge weights (see Attributes) are written, if any
+func (g *Graph) DumpMETIS(writer io.Writer, weights *METISWeights) error {
+	if weights == nil && g.Attrs != nil && (g.Attrs.VertexWeights != nil ||
+		g.Attrs.EdgeWeights != nil) {
+
+		weights = &METISWeights{Edge: g.Attrs.EdgeWeights}
+		if g.Attrs.VertexWeights != nil {
+			weights.Vertex = make([][]int, len(g.Vertices))
+			for i, w := range g.Attrs.VertexWeights {
+				weights.Vertex[i] = []int{w}
+			}
+		}
+	}
+
+	nAdj := 0
+	for i := range g.Vertices {
+		nAdj += len(g.Vertices[i].Adj)
+	}
+
+	// build header, with FMT and NCON only if needed
+	header := fmt.Sprintf("%d %d", len(g.Vertices), nAdj/2)
+	ncon := 0
+	if weights != nil {
+		format := ""
+		for _, present := range []bool{weights.Sizes != nil,
+			weights.Vertex != nil, weights.Edge != nil} {
+
+			if present {
+				format += "1"
+			} else {
+				format += "0"
+			}
+		}
+		header += " " + format
+		if weights.Vertex != nil && len(weights.Vertex) > 0 {
+			ncon = len(weights.Vertex[0])
+			if ncon > 1 {
+				header += " " + strconv.Itoa(ncon)
+			}
+		}
+	}
+	_, err := io.WriteString(writer, header+"\n")
+	if err != nil {
+		return err
+	}
+
+	// write one line per vertex
+	for i := range g.Vertices {
+		fields := make([]string, 0)
+		if weights != nil && weights.Sizes != nil {
+			fields = append(fields, strconv.Itoa(weights.Sizes[i]))
+		}
+		if weights != nil && weights.Vertex != nil {
+			if len(weights.Vertex[i]) != ncon {
+				return fmt.Errorf("metis: vertex %d has %d weights, "+
+					"expected %d", i, len(weights.Vertex[i]), ncon)
+			}
+			for _, w := range weights.Vertex[i] {
+				fields = append(fields, strconv.Itoa(w))
+			}
+		}
+		for k, j := range g.Vertices[i].Adj {
+			fields = append(fields, strconv.Itoa(j+1))
+			if weights != nil && weights.Edge != nil {
+				fields = append(fields, strconv.Itoa(weights.Edge[i][k]))
+			}
+		}
+
+		_, err = io.WriteString(writer, strings.Join(fields, " ")+"\n")
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
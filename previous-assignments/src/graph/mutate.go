@@ -0,0 +1,247 @@
+package graph
+
+import "sort"
+
+// HasEdge reports whether there is an edge between two nodes in a graph; it
+// scans the shorter of the two adjacency lists, which may be unsorted
+func (g *Graph) HasEdge(n1, n2 int) bool {
+	if n1 >= len(g.Vertices) || n2 >= len(g.Vertices) {
+		panic("Invalid node indices")
+	}
+
+	if len(g.Vertices[n1].Adj) > len(g.Vertices[n2].Adj) {
+		n1, n2 = n2, n1
+	}
+	for _, j := range g.Vertices[n1].Adj {
+		if j == n2 {
+			return true
+		}
+	}
+	return false
+}
+
+// removeNeighbor removes all occurrences of neighbor j from vertex i's
+// adjacency list (and its edge weights), keeping the others in order, and
+// returns how many were removed
+func (g *Graph) removeNeighbor(i, j int) int {
+	var weights []int
+	if g.Attrs != nil && g.Attrs.EdgeWeights != nil {
+		weights = g.Attrs.EdgeWeights[i]
+	}
+
+	v := &g.Vertices[i]
+	n := 0
+	for k, l := range v.Adj {
+		if l == j {
+			continue
+		}
+		v.Adj[n] = l
+		if weights != nil {
+			weights[n] = weights[k]
+		}
+		n++
+	}
+
+	nRemoved := len(v.Adj) - n
+	v.Adj = v.Adj[:n]
+	if weights != nil {
+		g.Attrs.EdgeWeights[i] = weights[:n]
+	}
+	return nRemoved
+}
+
+// RemoveEdge removes the undirected edge between two nodes in a graph,
+// including any duplicates of it, and reports whether there was one
+func (g *Graph) RemoveEdge(n1, n2 int) bool {
+	if n1 >= len(g.Vertices) || n2 >= len(g.Vertices) {
+		panic("Invalid node indices")
+	}
+
+	nRemoved := g.removeNeighbor(n1, n2)
+	if n1 != n2 {
+		g.removeNeighbor(n2, n1)
+	}
+	return nRemoved > 0
+}
+
+// RemoveVertex removes a node and all of its edges from a graph, see
+// RemoveVertices
+func (g *Graph) RemoveVertex(n int) []int {
+	return g.RemoveVertices([]int{n})
+}
+
+// RemoveVertices removes a set of nodes and all of their edges from a graph.
+// The remaining vertices are compacted, keeping their order, so indices
+// change; the returned remap table gives the new index of each old index, or
+// -1 for removed vertices. This rewrites every adjacency list, so removing
+// many vertices at once is much faster than removing them one at a time
+func (g *Graph) RemoveVertices(vertices []int) []int {
+	remap := make([]int, len(g.Vertices))
+	for _, i := range vertices {
+		if i < 0 || i >= len(g.Vertices) {
+			panic("Invalid node indices")
+		}
+		remap[i] = -1
+	}
+	nVertices := 0
+	for i := range remap {
+		if remap[i] != -1 {
+			remap[i] = nVertices
+			nVertices++
+		}
+	}
+
+	attrs := g.Attrs
+	if attrs == nil {
+		attrs = &Attributes{}
+	}
+
+	for i := range g.Vertices {
+		n := remap[i]
+		if n == -1 {
+			continue
+		}
+
+		// renumber neighbors, dropping removed ones
+		var weights []int
+		if attrs.EdgeWeights != nil {
+			weights = attrs.EdgeWeights[i]
+		}
+		adj := g.Vertices[i].Adj[:0]
+		for k, j := range g.Vertices[i].Adj {
+			if remap[j] == -1 {
+				continue
+			}
+			if weights != nil {
+				weights[len(adj)] = weights[k]
+			}
+			adj = append(adj, remap[j])
+		}
+
+		// move vertex to its new index (fields are copied one by one since
+		// Vertex holds a Mutex)
+		g.Vertices[n].Value = g.Vertices[i].Value
+		g.Vertices[n].Adj = adj
+		if attrs.VertexWeights != nil {
+			attrs.VertexWeights[n] = attrs.VertexWeights[i]
+		}
+		if attrs.EdgeWeights != nil {
+			attrs.EdgeWeights[n] = weights[:len(adj)]
+		}
+		if attrs.VertexLabels != nil {
+			attrs.VertexLabels[n] = attrs.VertexLabels[i]
+		}
+	}
+
+	g.Vertices = g.Vertices[:nVertices]
+	if attrs.VertexWeights != nil {
+		attrs.VertexWeights = attrs.VertexWeights[:nVertices]
+	}
+	if attrs.EdgeWeights != nil {
+		attrs.EdgeWeights = attrs.EdgeWeights[:nVertices]
+	}
+	if attrs.VertexLabels != nil {
+		attrs.VertexLabels = attrs.VertexLabels[:nVertices]
+	}
+
+	return remap
+}
+
+// Canonicalize sorts each vertex's adjacency list and drops duplicate
+// neighbors (keeping the first edge weight of each), e.g., after
+// symmetrizing a list of edges; self-loops are kept (see Validate)
+func (g *Graph) Canonicalize() {
+	for i := range g.Vertices {
+		v := &g.Vertices[i]
+
+		if g.Attrs == nil || g.Attrs.EdgeWeights == nil {
+			sort.Ints(v.Adj)
+			adj := v.Adj[:0]
+			for k, j := range v.Adj {
+				if k == 0 || j != v.Adj[k-1] {
+					adj = append(adj, j)
+				}
+			}
+			v.Adj = adj
+			continue
+		}
+
+		// sort neighbors and weights together
+		weights := g.Attrs.EdgeWeights[i]
+		sort.Stable(adjWeights{v.Adj, weights})
+		n := 0
+		for k, j := range v.Adj {
+			if k == 0 || j != v.Adj[n-1] {
+				v.Adj[n] = j
+				weights[n] = weights[k]
+				n++
+			}
+		}
+		v.Adj = v.Adj[:n]
+		g.Attrs.EdgeWeights[i] = weights[:n]
+	}
+}
+
+// adjWeights sorts an adjacency list along with its edge weights
+type adjWeights struct {
+	adj     []int
+	weights []int
+}
+
+func (a adjWeights) Len() int           { return len(a.adj) }
+func (a adjWeights) Less(i, j int) bool { return a.adj[i] < a.adj[j] }
+func (a adjWeights) Swap(i, j int) {
+	a.adj[i], a.adj[j] = a.adj[j], a.adj[i]
+	a.weights[i], a.weights[j] = a.weights[j], a.weights[i]
+}
+
+// InducedSubgraph returns the subgraph induced by a set of vertices, i.e.,
+// those vertices and all edges between them, with their values and
+// attributes. Vertex k of the subgraph is vertices[k] of the graph; this
+// mapping is also returned as an IDMap (e.g., for DumpColoring)
+func (g *Graph) InducedSubgraph(vertices []int) (*Graph, IDMap) {
+	index := make(map[int]int, len(vertices))
+	ids := make(IDMap, len(vertices))
+	for k, i := range vertices {
+		if i < 0 || i >= len(g.Vertices) {
+			panic("Invalid node indices")
+		}
+		if _, ok := index[i]; ok {
+			panic("Duplicate node in induced subgraph")
+		}
+		index[i] = k
+		ids[k] = int64(i)
+	}
+
+	sub := New(len(vertices))
+	if g.Attrs != nil {
+		sub.NewAttributes(g.Attrs.VertexWeights != nil,
+			g.Attrs.EdgeWeights != nil, g.Attrs.VertexLabels != nil)
+	}
+
+	for k, i := range vertices {
+		sub.Vertices[k].Value = g.Vertices[i].Value
+		for l, j := range g.Vertices[i].Adj {
+			subJ, ok := index[j]
+			if !ok {
+				continue
+			}
+			sub.Vertices[k].Adj = append(sub.Vertices[k].Adj, subJ)
+			if sub.Attrs != nil && sub.Attrs.EdgeWeights != nil {
+				sub.Attrs.EdgeWeights[k] = append(sub.Attrs.EdgeWeights[k],
+					g.Attrs.EdgeWeights[i][l])
+			}
+		}
+
+		if sub.Attrs != nil {
+			if sub.Attrs.VertexWeights != nil {
+				sub.Attrs.VertexWeights[k] = g.Attrs.VertexWeights[i]
+			}
+			if sub.Attrs.VertexLabels != nil {
+				sub.Attrs.VertexLabels[k] = g.Attrs.VertexLabels[i]
+			}
+		}
+	}
+
+	return &sub, ids
+}
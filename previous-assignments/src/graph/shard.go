@@ -0,0 +1,164 @@
+package graph
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+// VertexReader reads the vertices of a (possibly compressed) graph file in
+// order, one at a time, without loading the graph, e.g., so that the proj2
+// server can shard files larger than memory (such as those written by the
+// streaming generators). Both the text and the binary format are read, and
+// each vertex is returned as a line of the text format. For the binary
+// format, only the offsets are kept in memory, colors aren't read (they're
+// written as 0), and the checksum is verified after the last vertex
+type VertexReader struct {
+	NumVertices int    // number of vertices of the graph
+	Flags       string // attribute flags of the text header (see Dump)
+
+	next    int // index of the next vertex
+	scanner *bufio.Scanner
+
+	// binary format
+	reader   io.Reader // reader of the adjacency entries, hashing them
+	source   io.Reader // underlying reader, for the checksum itself
+	checksum hash.Hash32
+	header   binaryHeader
+	offsets  []int32
+}
+
+// NewVertexReader reads the header of a graph file, in the text or binary
+// format, and returns a VertexReader for its vertices
+func NewVertexReader(reader io.Reader) (*VertexReader, error) {
+	bufReader, err := Decompress(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	vr := &VertexReader{}
+	if !IsBinary(bufReader) {
+		vr.scanner = bufio.NewScanner(bufReader)
+		vr.scanner.Scan()
+		vr.NumVertices, vr.Flags, err = ParseTextHeader(vr.scanner.Text())
+		if err != nil {
+			return nil, err
+		}
+		return vr, nil
+	}
+
+	vr.checksum = crc32.NewIEEE()
+	vr.source = bufReader
+	vr.reader = io.TeeReader(bufReader, vr.checksum)
+	if vr.header, err = readBinaryHeader(vr.reader); err != nil {
+		return nil, err
+	}
+	vr.offsets, err = readInt32s(vr.reader, int(vr.header.NVertices)+1)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkBinaryOffsets(vr.header, vr.offsets); err != nil {
+		return nil, err
+	}
+	vr.NumVertices = int(vr.header.NVertices)
+	return vr, nil
+}
+
+// Next returns the next vertex as a line of the text format, or io.EOF
+// after the last vertex. Vertices missing from a text file are read as
+// disconnected vertices, and lines past its vertex count are ignored
+func (vr *VertexReader) Next() (string, error) {
+	if vr.next == vr.NumVertices {
+		if vr.scanner == nil {
+			if err := vr.verifyChecksum(); err != nil {
+				return "", err
+			}
+		}
+		return "", io.EOF
+	}
+	i := vr.next
+	vr.next++
+
+	if vr.scanner != nil {
+		if vr.scanner.Scan() {
+			return vr.scanner.Text(), nil
+		}
+		if err := vr.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "0;", nil
+	}
+
+	adj, err := readInt32s(vr.reader, int(vr.offsets[i+1]-vr.offsets[i]))
+	if err != nil {
+		return "", err
+	}
+	if err := checkBinaryNeighbors(vr.header, adj); err != nil {
+		return "", err
+	}
+	neighbors := make([]string, len(adj))
+	for k, j := range adj {
+		neighbors[k] = strconv.Itoa(int(j))
+	}
+	return "0;" + strings.Join(neighbors, ","), nil
+}
+
+// verifyChecksum skips the colors of a binary file, if any, and checks its
+// checksum
+func (vr *VertexReader) verifyChecksum() error {
+	if vr.header.Flags&BINARY_FLAG_COLORS != 0 {
+		_, err := io.CopyN(ioutil.Discard, vr.reader,
+			4*int64(vr.header.NVertices))
+		if err != nil {
+			return err
+		}
+	}
+
+	// checksum is read from the underlying reader so it isn't hashed itself
+	sum := vr.checksum.Sum32()
+	var expected uint32
+	err := binary.Read(vr.source, binary.LittleEndian, &expected)
+	if err != nil {
+		return err
+	}
+	if sum != expected {
+		return ErrBadChecksum
+	}
+	return nil
+}
+
+// WriteSubgraph reads the next nVertices vertices and writes them to file as
+// a graph in the text format with the same attributes, e.g., to send one
+// shard of a graph to a worker; neighbor indices aren't renumbered, so they
+// still refer to the whole graph
+func (vr *VertexReader) WriteSubgraph(writer io.Writer,
+	nVertices int) error {
+
+	header := strconv.Itoa(nVertices)
+	if vr.Flags != "" {
+		header += " " + vr.Flags
+	}
+	if _, err := io.WriteString(writer, header); err != nil {
+		return err
+	}
+
+	for k := 0; k < nVertices; k++ {
+		line, err := vr.Next()
+		if err == io.EOF {
+			return fmt.Errorf("graph: subgraph past the last vertex %d",
+				vr.NumVertices-1)
+		} else if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(writer, "\n"+line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
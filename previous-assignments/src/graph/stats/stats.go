@@ -0,0 +1,138 @@
+// Package stats computes statistics and structural properties of graphs,
+// e.g., to choose coloring bounds or to describe test inputs
+package stats
+
+import (
+	"encoding/json"
+	"fmt"
+	"graph"
+	"io"
+)
+
+// Stats holds the statistics of a graph computed by Compute
+type Stats struct {
+	NumVertices int `json:"num_vertices"`
+	NumEdges    int `json:"num_edges"` // undirected edges
+
+	MinDegree       int     `json:"min_degree"`
+	MaxDegree       int     `json:"max_degree"` // Delta
+	AvgDegree       float64 `json:"avg_degree"`
+	DegreeHistogram []int   `json:"degree_histogram"` // count of each degree
+
+	NumComponents    int `json:"num_components"`
+	LargestComponent int `json:"largest_component"` // number of vertices
+
+	Degeneracy int `json:"degeneracy"` // largest core number
+
+	NumTriangles int     `json:"num_triangles"`
+	Clustering   float64 `json:"clustering"`   // average local coefficient
+	Transitivity float64 `json:"transitivity"` // global coefficient
+
+	IsBipartite bool `json:"is_bipartite"`
+}
+
+// Compute computes all statistics of a graph. The structural statistics
+// assume a simple undirected graph, i.e., without self-loops or duplicate
+// edges (see graph.Validate)
+func Compute(g graph.Interface) *Stats {
+	s := &Stats{
+		NumVertices:     g.NumVertices(),
+		NumEdges:        NumEdges(g),
+		MaxDegree:       MaxDegree(g),
+		DegreeHistogram: DegreeHistogram(g),
+		Degeneracy:      Degeneracy(g),
+		IsBipartite:     IsBipartite(g),
+	}
+
+	if s.NumVertices > 0 {
+		s.AvgDegree = 2 * float64(s.NumEdges) / float64(s.NumVertices)
+		for s.DegreeHistogram[s.MinDegree] == 0 {
+			s.MinDegree++
+		}
+	}
+
+	components, nComponents := Components(g)
+	s.NumComponents = nComponents
+	sizes := make([]int, nComponents)
+	for _, c := range components {
+		sizes[c]++
+		if sizes[c] > s.LargestComponent {
+			s.LargestComponent = sizes[c]
+		}
+	}
+
+	triangles := Triangles(g)
+	nTriples := 0
+	for i, t := range triangles {
+		s.NumTriangles += t
+		d := g.Degree(i)
+		nTriples += d * (d - 1) / 2
+		if d >= 2 {
+			s.Clustering += float64(t) / float64(d*(d-1)/2)
+		}
+	}
+	// each triangle is counted at each of its three vertices
+	s.NumTriangles /= 3
+	if s.NumVertices > 0 {
+		s.Clustering /= float64(s.NumVertices)
+	}
+	if nTriples > 0 {
+		s.Transitivity = float64(3*s.NumTriangles) / float64(nTriples)
+	}
+
+	return s
+}
+
+// WriteJSON writes the statistics to file as JSON
+func (s *Stats) WriteJSON(writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
+
+// String returns a human-readable summary of the statistics
+func (s *Stats) String() string {
+	return fmt.Sprintf("vertices:      %d\n"+
+		"edges:         %d\n"+
+		"degree:        min %d, max %d, avg %f\n"+
+		"components:    %d (largest %d)\n"+
+		"degeneracy:    %d\n"+
+		"triangles:     %d\n"+
+		"clustering:    %f (transitivity %f)\n"+
+		"bipartite:     %t\n",
+		s.NumVertices, s.NumEdges, s.MinDegree, s.MaxDegree, s.AvgDegree,
+		s.NumComponents, s.LargestComponent, s.Degeneracy, s.NumTriangles,
+		s.Clustering, s.Transitivity, s.IsBipartite)
+}
+
+// NumEdges returns the number of undirected edges of a graph, i.e., half the
+// number of adjacency entries
+func NumEdges(g graph.Interface) int {
+	nAdj := 0
+	for i := 0; i < g.NumVertices(); i++ {
+		nAdj += g.Degree(i)
+	}
+	return nAdj / 2
+}
+
+// MaxDegree returns the largest degree of a graph (Delta); greedy colorers
+// never need more than MaxDegree+1 colors
+func MaxDegree(g graph.Interface) int {
+	max := 0
+	for i := 0; i < g.NumVertices(); i++ {
+		if g.Degree(i) > max {
+			max = g.Degree(i)
+		}
+	}
+	return max
+}
+
+// DegreeHistogram returns the degree distribution of a graph: element d is
+// the number of vertices with degree d
+func DegreeHistogram(g graph.Interface) []int {
+	histogram := make([]int, MaxDegree(g)+1)
+	for i := 0; i < g.NumVertices(); i++ {
+		histogram[g.Degree(i)]++
+	}
+	return histogram
+}
@@ -0,0 +1,218 @@
+package stats
+
+import (
+	"graph"
+	"sort"
+)
+
+// Components labels the connected components of a graph by breadth-first
+// search, returning each vertex's component (numbered from 0 in order of
+// their lowest vertex) and the number of components
+func Components(g graph.Interface) ([]int, int) {
+	nVertices := g.NumVertices()
+	components := make([]int, nVertices)
+	for i := range components {
+		components[i] = -1
+	}
+
+	nComponents := 0
+	queue := make([]int, 0)
+	for root := 0; root < nVertices; root++ {
+		if components[root] != -1 {
+			continue
+		}
+
+		components[root] = nComponents
+		queue = append(queue[:0], root)
+		for len(queue) > 0 {
+			i := queue[0]
+			queue = queue[1:]
+			for k := 0; k < g.Degree(i); k++ {
+				j := g.Neighbor(i, k)
+				if components[j] == -1 {
+					components[j] = nComponents
+					queue = append(queue, j)
+				}
+			}
+		}
+		nComponents++
+	}
+
+	return components, nComponents
+}
+
+// IsBipartite reports whether a graph is 2-colorable, by trying to 2-color
+// each component by breadth-first search
+func IsBipartite(g graph.Interface) bool {
+	nVertices := g.NumVertices()
+	sides := make([]int, nVertices)
+	for i := range sides {
+		sides[i] = -1
+	}
+
+	queue := make([]int, 0)
+	for root := 0; root < nVertices; root++ {
+		if sides[root] != -1 {
+			continue
+		}
+
+		sides[root] = 0
+		queue = append(queue[:0], root)
+		for len(queue) > 0 {
+			i := queue[0]
+			queue = queue[1:]
+			for k := 0; k < g.Degree(i); k++ {
+				j := g.Neighbor(i, k)
+				if sides[j] == -1 {
+					sides[j] = 1 - sides[i]
+					queue = append(queue, j)
+				} else if sides[j] == sides[i] {
+					return false
+				}
+			}
+		}
+	}
+
+	return true
+}
+
+// CoreNumbers returns the core number of each vertex, i.e., the largest k
+// such that the vertex is in the k-core (the maximal subgraph with minimum
+// degree k), using the O(n+m) bucket algorithm from Matula and Beck,
+// "Smallest-last ordering and clustering and graph coloring algorithms".
+// Vertices are repeatedly removed in order of smallest remaining degree
+func CoreNumbers(g graph.Interface) []int {
+	_, cores := degeneracyOrdering(g)
+	return cores
+}
+
+// DegeneracyOrdering returns the vertices in the order in which CoreNumbers
+// removes them, i.e., each vertex has the smallest degree in the subgraph
+// induced by itself and the vertices after it. Greedy coloring in the
+// reverse order (smallest-last) uses at most Degeneracy+1 colors
+func DegeneracyOrdering(g graph.Interface) []int {
+	order, _ := degeneracyOrdering(g)
+	return order
+}
+
+// degeneracyOrdering computes both DegeneracyOrdering and CoreNumbers
+func degeneracyOrdering(g graph.Interface) ([]int, []int) {
+	nVertices := g.NumVertices()
+	degrees := make([]int, nVertices)
+	maxDegree := 0
+	for i := range degrees {
+		degrees[i] = g.Degree(i)
+		if degrees[i] > maxDegree {
+			maxDegree = degrees[i]
+		}
+	}
+
+	// bucket sort vertices by degree: order holds the vertices sorted by
+	// current degree, position their index in order, and start the index of
+	// the first vertex of each degree
+	start := make([]int, maxDegree+2)
+	for _, d := range degrees {
+		start[d+1]++
+	}
+	for d := 1; d < len(start); d++ {
+		start[d] += start[d-1]
+	}
+	order := make([]int, nVertices)
+	position := make([]int, nVertices)
+	next := append([]int(nil), start...)
+	for i, d := range degrees {
+		position[i] = next[d]
+		order[position[i]] = i
+		next[d]++
+	}
+
+	// remove vertices in order; decrementing a neighbor's degree moves it to
+	// the front of its bucket, which then starts one later (this never
+	// moves the vertices already removed, so order ends up as the removal
+	// order)
+	for k := 0; k < nVertices; k++ {
+		i := order[k]
+		for l := 0; l < g.Degree(i); l++ {
+			j := g.Neighbor(i, l)
+			if degrees[j] <= degrees[i] {
+				continue
+			}
+
+			d := degrees[j]
+			first := order[start[d]]
+			if first != j {
+				order[position[j]], order[start[d]] = first, j
+				position[first], position[j] = position[j], start[d]
+			}
+			start[d]++
+			degrees[j]--
+		}
+	}
+
+	return order, degrees
+}
+
+// Degeneracy returns the degeneracy of a graph, i.e., its largest core
+// number; smallest-last greedy coloring uses at most Degeneracy+1 colors
+func Degeneracy(g graph.Interface) int {
+	max := 0
+	for _, core := range CoreNumbers(g) {
+		if core > max {
+			max = core
+		}
+	}
+	return max
+}
+
+// Triangles returns the number of triangles through each vertex. Each edge is
+// directed from its endpoint of lower degree to the one of higher degree
+// (breaking ties by index), and each triangle is then found exactly once
+// from its lowest vertex, in O(m^1.5) time overall
+func Triangles(g graph.Interface) []int {
+	nVertices := g.NumVertices()
+	lower := func(i, j int) bool {
+		di, dj := g.Degree(i), g.Degree(j)
+		return di < dj || (di == dj && i < j)
+	}
+
+	// out-neighbors, without self-loops and duplicates
+	out := make([][]int, nVertices)
+	for i := range out {
+		for k := 0; k < g.Degree(i); k++ {
+			if j := g.Neighbor(i, k); j != i && lower(i, j) {
+				out[i] = append(out[i], j)
+			}
+		}
+		sort.Ints(out[i])
+		n := 0
+		for k, j := range out[i] {
+			if k == 0 || j != out[i][n-1] {
+				out[i][n] = j
+				n++
+			}
+		}
+		out[i] = out[i][:n]
+	}
+
+	triangles := make([]int, nVertices)
+	marked := make([]bool, nVertices)
+	for i := range out {
+		for _, j := range out[i] {
+			marked[j] = true
+		}
+		for _, j := range out[i] {
+			for _, l := range out[j] {
+				if marked[l] {
+					triangles[i]++
+					triangles[j]++
+					triangles[l]++
+				}
+			}
+		}
+		for _, j := range out[i] {
+			marked[j] = false
+		}
+	}
+
+	return triangles
+}
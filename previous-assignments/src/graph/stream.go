@@ -0,0 +1,399 @@
+package graph
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// The streaming generators write graphs that are larger than memory, in the
+// text or binary format, without ever holding the whole graph. Edges are
+// generated in blocks, sorted in bounded runs that are spilled to temporary
+// files, and the runs are then merged so that each vertex's adjacency list
+// is written in vertex order (an external merge sort). Only the current
+// runs' edges, plus one degree per vertex for the binary format, are kept in
+// memory. The output is the same as Dump (or DumpBinary) of the
+// corresponding in-memory generator, and the proj2 server can shard it
+// directly in either format (see VertexReader)
+
+// StreamOptions configures the streaming generators
+type StreamOptions struct {
+	Binary   bool   // write the binary format rather than the text format
+	NThreads int    // number of threads generating edges
+	RunSize  int    // max adjacency entries sorted in memory at once
+	TempDir  string // directory for temporary run files ("" for default)
+}
+
+// DefaultRunSize is the run size used if StreamOptions.RunSize is 0 (about
+// 32MB of adjacency entries)
+const DefaultRunSize = 1 << 22
+
+// streamBlockEdges is the (expected) number of edges generated at once by
+// each thread of the streaming generators
+const streamBlockEdges = 1 << 16
+
+// edgeSource generates the edges of a graph in independent blocks, which
+// may contain self-loops and duplicates (they are dropped when merging)
+type edgeSource struct {
+	nVertices int
+	nBlocks   int
+	block     func(b int) [][2]int
+}
+
+// WriteRandomGraph streams the same graph as NewRandomGraph to file
+func WriteRandomGraph(writer io.Writer, nVertices int, degree float32,
+	seed uint64, opts StreamOptions) error {
+
+	pEdge := float64(degree) / float64(nVertices-1)
+
+	// vertex i has about pEdge*i lower edges, so blocks of vertices can't
+	// be much larger than streamBlockEdges
+	verticesPerBlock := int(streamBlockEdges / math.Max(float64(degree), 1))
+	if verticesPerBlock < 1 {
+		verticesPerBlock = 1
+	}
+
+	return writeStream(writer, edgeSource{
+		nVertices: nVertices,
+		nBlocks:   (nVertices + verticesPerBlock - 1) / verticesPerBlock,
+		block: func(b int) [][2]int {
+			edges := make([][2]int, 0)
+			start := b * verticesPerBlock
+			for i := start; i < start+verticesPerBlock && i < nVertices; i++ {
+				edges = sampleLowerEdges(edges, i, pEdge, seed)
+			}
+			return edges
+		},
+	}, opts)
+}
+
+// WriteRMATGraph streams the same graph as NewRMATGraph to file; unlike
+// WriteRMATEdgeList, duplicate edges are dropped and the output can be
+// loaded (or sharded) like any other graph file
+func WriteRMATGraph(writer io.Writer, params RMATParams,
+	opts StreamOptions) error {
+
+	gen := newRMATGenerator(params)
+	nEdges := gen.nEdges()
+
+	return writeStream(writer, edgeSource{
+		nVertices: 1 << params.Scale,
+		nBlocks:   (nEdges + streamBlockEdges - 1) / streamBlockEdges,
+		block: func(b int) [][2]int {
+			end := (b + 1) * streamBlockEdges
+			if end > nEdges {
+				end = nEdges
+			}
+			return gen.generateBlock(b*streamBlockEdges, end)
+		},
+	}, opts)
+}
+
+// streamRun is a sorted run of adjacency entries spilled to a temporary file
+type streamRun struct {
+	file *os.File
+}
+
+// writeStream generates sorted runs from an edge source and merges them to
+// file in the requested format
+func writeStream(writer io.Writer, source edgeSource,
+	opts StreamOptions) error {
+
+	if opts.NThreads < 1 {
+		opts.NThreads = 1
+	}
+	if opts.RunSize < 1 {
+		opts.RunSize = DefaultRunSize
+	}
+	if source.nVertices > math.MaxInt32 {
+		return errors.New("graph: too many vertices to stream")
+	}
+
+	runs, err := generateRuns(source, opts)
+	defer func() {
+		for _, run := range runs {
+			run.file.Close()
+			os.Remove(run.file.Name())
+		}
+	}()
+	if err != nil {
+		return err
+	}
+
+	if opts.Binary {
+		return mergeRunsBinary(writer, runs, source.nVertices)
+	}
+	return mergeRunsText(writer, runs, source.nVertices)
+}
+
+// generateRuns generates the blocks of an edge source, nThreads at a time,
+// and spills the adjacency entries (both directions of every edge) to a new
+// sorted run whenever there are at least RunSize of them
+func generateRuns(source edgeSource, opts StreamOptions) ([]streamRun,
+	error) {
+
+	runs := make([]streamRun, 0)
+	entries := make([][2]uint32, 0)
+	buffers := make([][][2]int, opts.NThreads)
+	var wg sync.WaitGroup
+
+	for start := 0; start < source.nBlocks; start += opts.NThreads {
+		wg.Add(opts.NThreads)
+		for i := 0; i < opts.NThreads; i++ {
+			go func(i int) {
+				defer wg.Done()
+				buffers[i] = nil
+				if start+i < source.nBlocks {
+					buffers[i] = source.block(start + i)
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		for _, buffer := range buffers {
+			for _, edge := range buffer {
+				if edge[0] == edge[1] {
+					continue
+				}
+				u, v := uint32(edge[0]), uint32(edge[1])
+				entries = append(entries, [2]uint32{u, v}, [2]uint32{v, u})
+			}
+		}
+
+		isLast := start+opts.NThreads >= source.nBlocks
+		if len(entries) >= opts.RunSize || (isLast && len(entries) > 0) {
+			run, err := spillRun(entries, opts.TempDir)
+			if err != nil {
+				return runs, err
+			}
+			runs = append(runs, run)
+			entries = entries[:0]
+		}
+	}
+
+	return runs, nil
+}
+
+// spillRun sorts adjacency entries and writes them to a temporary file
+func spillRun(entries [][2]uint32, tempDir string) (streamRun, error) {
+	sort.Slice(entries, func(a, b int) bool {
+		if entries[a][0] != entries[b][0] {
+			return entries[a][0] < entries[b][0]
+		}
+		return entries[a][1] < entries[b][1]
+	})
+
+	file, err := ioutil.TempFile(tempDir, "graphrun")
+	if err != nil {
+		return streamRun{}, err
+	}
+	run := streamRun{file}
+
+	writer := bufio.NewWriter(file)
+	buf := make([]byte, 8)
+	for _, entry := range entries {
+		binary.LittleEndian.PutUint32(buf[:4], entry[0])
+		binary.LittleEndian.PutUint32(buf[4:], entry[1])
+		if _, err := writer.Write(buf); err != nil {
+			return run, err
+		}
+	}
+	return run, writer.Flush()
+}
+
+// runReader reads the adjacency entries of a run in order
+type runReader struct {
+	reader *bufio.Reader
+	entry  [2]uint32
+	buf    []byte
+}
+
+// next reads the next entry of a run, returning io.EOF at the end
+func (r *runReader) next() error {
+	if _, err := io.ReadFull(r.reader, r.buf); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return errors.New("graph: truncated run file")
+		}
+		return err
+	}
+	r.entry[0] = binary.LittleEndian.Uint32(r.buf[:4])
+	r.entry[1] = binary.LittleEndian.Uint32(r.buf[4:])
+	return nil
+}
+
+// runHeap is a min-heap of run readers by their current entry
+type runHeap []*runReader
+
+func (h runHeap) Len() int { return len(h) }
+func (h runHeap) Less(a, b int) bool {
+	if h[a].entry[0] != h[b].entry[0] {
+		return h[a].entry[0] < h[b].entry[0]
+	}
+	return h[a].entry[1] < h[b].entry[1]
+}
+func (h runHeap) Swap(a, b int)       { h[a], h[b] = h[b], h[a] }
+func (h *runHeap) Push(x interface{}) { *h = append(*h, x.(*runReader)) }
+func (h *runHeap) Pop() interface{} {
+	old := *h
+	r := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return r
+}
+
+// mergeRuns merges sorted runs, calling emit for every distinct adjacency
+// entry in (vertex, neighbor) order
+func mergeRuns(runs []streamRun, emit func(u, v int) error) error {
+	h := make(runHeap, 0, len(runs))
+	for _, run := range runs {
+		if _, err := run.file.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		r := &runReader{
+			reader: bufio.NewReader(run.file),
+			buf:    make([]byte, 8),
+		}
+		if err := r.next(); err == nil {
+			h = append(h, r)
+		} else if err != io.EOF {
+			return err
+		}
+	}
+	heap.Init(&h)
+
+	last := [2]uint32{math.MaxUint32, math.MaxUint32}
+	for len(h) > 0 {
+		r := h[0]
+		if r.entry != last {
+			last = r.entry
+			if err := emit(int(last[0]), int(last[1])); err != nil {
+				return err
+			}
+		}
+
+		if err := r.next(); err == io.EOF {
+			heap.Pop(&h)
+		} else if err != nil {
+			return err
+		} else {
+			heap.Fix(&h, 0)
+		}
+	}
+
+	return nil
+}
+
+// mergeRunsText merges sorted runs to file in the text format (see Dump),
+// with all vertex values set to 0
+func mergeRunsText(writer io.Writer, runs []streamRun, nVertices int) error {
+	bufWriter := bufio.NewWriter(writer)
+	_, err := bufWriter.WriteString(strconv.Itoa(nVertices) + "\n")
+	if err != nil {
+		return err
+	}
+
+	// line holds the current vertex's line; vertices without edges (between
+	// emitted entries) get an empty adjacency list
+	line := []byte("0;")
+	current := 0
+	finishLines := func(until int) error {
+		for ; current < until; current++ {
+			line = append(line, '\n')
+			if _, err := bufWriter.Write(line); err != nil {
+				return err
+			}
+			line = append(line[:0], "0;"...)
+		}
+		return nil
+	}
+
+	err = mergeRuns(runs, func(u, v int) error {
+		if err := finishLines(u); err != nil {
+			return err
+		}
+		if line[len(line)-1] != ';' {
+			line = append(line, ',')
+		}
+		line = strconv.AppendInt(line, int64(v), 10)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := finishLines(nVertices); err != nil {
+		return err
+	}
+	return bufWriter.Flush()
+}
+
+// mergeRunsBinary merges sorted runs to file in the binary format (see
+// DumpBinary), without colors. The offsets precede the adjacency entries, so
+// the runs are merged twice: once to count degrees, and once to write them
+func mergeRunsBinary(writer io.Writer, runs []streamRun,
+	nVertices int) error {
+
+	degrees := make([]uint32, nVertices)
+	nEdges := 0
+	err := mergeRuns(runs, func(u, _ int) error {
+		degrees[u]++
+		nEdges++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if nEdges > math.MaxInt32 {
+		return errors.New("graph: too many edges for the binary format")
+	}
+
+	checksum := crc32.NewIEEE()
+	bufWriter := bufio.NewWriter(io.MultiWriter(writer, checksum))
+
+	header := binaryHeader{
+		Version:   BinaryVersion,
+		NVertices: uint32(nVertices),
+		NEdges:    uint32(nEdges),
+	}
+	copy(header.Magic[:], BinaryMagic)
+	if err := binary.Write(bufWriter, binary.LittleEndian, header); err != nil {
+		return err
+	}
+
+	buf := make([]byte, 4)
+	writeInt32 := func(x int) error {
+		binary.LittleEndian.PutUint32(buf, uint32(x))
+		_, err := bufWriter.Write(buf)
+		return err
+	}
+
+	offset := 0
+	for i := 0; i <= nVertices; i++ {
+		if err := writeInt32(offset); err != nil {
+			return err
+		}
+		if i < nVertices {
+			offset += int(degrees[i])
+		}
+	}
+
+	err = mergeRuns(runs, func(_, v int) error {
+		return writeInt32(v)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := bufWriter.Flush(); err != nil {
+		return err
+	}
+	return binary.Write(writer, binary.LittleEndian, checksum.Sum32())
+}
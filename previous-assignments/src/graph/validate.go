@@ -0,0 +1,281 @@
+package graph
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ValidationErrorKind is the type of problem found by LoadStrict or Validate
+type ValidationErrorKind int
+
+const (
+	// ERR_MALFORMED_LINE means a line couldn't be parsed (not repairable)
+	ERR_MALFORMED_LINE ValidationErrorKind = iota
+	// ERR_COUNT_MISMATCH means the vertex count in the header doesn't match
+	// the number of vertex lines
+	ERR_COUNT_MISMATCH ValidationErrorKind = iota
+	// ERR_OUT_OF_RANGE means a neighbor index is not a vertex (not repairable)
+	ERR_OUT_OF_RANGE ValidationErrorKind = iota
+	// ERR_SELF_LOOP means a vertex is listed as its own neighbor
+	ERR_SELF_LOOP ValidationErrorKind = iota
+	// ERR_DUPLICATE_EDGE means a neighbor is listed more than once
+	ERR_DUPLICATE_EDGE ValidationErrorKind = iota
+	// ERR_MISSING_REVERSE_EDGE means j is a neighbor of i but not vice versa
+	ERR_MISSING_REVERSE_EDGE ValidationErrorKind = iota
+)
+
+// repairable reports whether a problem can be fixed in repair mode
+func (kind ValidationErrorKind) repairable() bool {
+	return kind != ERR_MALFORMED_LINE && kind != ERR_OUT_OF_RANGE
+}
+
+// ValidationError describes a problem with a graph file; Line is the
+// 1-based line number in the text format (the vertex count is line 1 and
+// vertex i is on line i+2)
+type ValidationError struct {
+	Kind ValidationErrorKind
+	Line int
+	Msg  string
+}
+
+// Error implements the error interface
+func (err *ValidationError) Error() string {
+	return fmt.Sprintf("graph line %d: %s", err.Line, err.Msg)
+}
+
+// vertexLine returns the line number of vertex i in the text format
+func vertexLine(i int) int {
+	return i + 2
+}
+
+// parseTextLine parses one "value;adj,adj[;attr...]" vertex line of the
+// text format, returning the unparsed attribute components (see Attributes)
+func parseTextLine(text string, lineNo int, flags string) (int, []int,
+	[]string, error) {
+
+	malformed := func(msg string) error {
+		return &ValidationError{ERR_MALFORMED_LINE, lineNo, msg}
+	}
+
+	// split into value, adj list and attributes; labels come last, so they
+	// may contain the separator
+	vertexComponents := strings.SplitN(text, ";", 2+len(flags))
+	if len(vertexComponents) < 2 {
+		return 0, nil, nil, malformed(fmt.Sprintf("expected "+
+			"\"value;adj,...\", got %q", text))
+	}
+	attrs := vertexComponents[2:]
+
+	// get vertex value
+	value, err := strconv.Atoi(vertexComponents[0])
+	if err != nil {
+		return 0, nil, nil, malformed(fmt.Sprintf("invalid value %q",
+			vertexComponents[0]))
+	}
+
+	// get vertex adjacency list, or skip if no adjacent vertices
+	if len(vertexComponents[1]) == 0 {
+		return value, nil, attrs, nil
+	}
+	adjComponents := strings.Split(vertexComponents[1], ",")
+	adj := make([]int, len(adjComponents))
+	for k, component := range adjComponents {
+		adj[k], err = strconv.Atoi(component)
+		if err != nil {
+			return 0, nil, nil, malformed(fmt.Sprintf("invalid neighbor "+
+				"%q", component))
+		}
+	}
+
+	return value, adj, attrs, nil
+}
+
+// LoadStrict reads a graph from file like Load, but validates it: malformed
+// lines, vertex count mismatches, out-of-range neighbors, self-loops,
+// duplicate edges and missing reverse edges are reported as
+// *ValidationError. If repair is false, the first problem is returned as an
+// error; otherwise the fixable problems are repaired (the vertex count is
+// taken from the number of vertex lines, self-loops and duplicates are
+// dropped and missing reverse edges are added) and returned as a list,
+// and only unfixable problems are returned as an error
+func LoadStrict(reader io.Reader, repair bool) (*Graph, []*ValidationError,
+	error) {
+
+	bufReader, err := Decompress(reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// binary files have no lines, but their structure can still be checked
+	if IsBinary(bufReader) {
+		g, err := LoadBinary(bufReader)
+		if err != nil {
+			return nil, nil, err
+		}
+		repaired, err := g.Validate(repair)
+		if err != nil {
+			return nil, nil, err
+		}
+		return g, repaired, nil
+	}
+
+	scanner := bufio.NewScanner(bufReader)
+	repaired := make([]*ValidationError, 0)
+
+	// get number of vertices
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, &ValidationError{ERR_MALFORMED_LINE, 1,
+			"missing vertex count"}
+	}
+	nVertices, flags, err := ParseTextHeader(scanner.Text())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// read in all vertices; don't trust the count until the end
+	g := New(0)
+	g.newAttrsFromFlags(flags)
+	for lineNo := 2; scanner.Scan(); lineNo++ {
+		value, adj, attrs, err := parseTextLine(scanner.Text(), lineNo,
+			flags)
+		if err != nil {
+			return nil, nil, err
+		}
+		i := len(g.Vertices)
+		g.AddNode(value)
+		g.Vertices[i].Adj = adj
+		if g.Attrs != nil {
+			err = g.setTextAttrs(i, attrs, flags, lineNo)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	if len(g.Vertices) != nVertices {
+		countErr := &ValidationError{ERR_COUNT_MISMATCH, 1,
+			fmt.Sprintf("header declares %d vertices, found %d", nVertices,
+				len(g.Vertices))}
+		if !repair {
+			return nil, nil, countErr
+		}
+		repaired = append(repaired, countErr)
+	}
+
+	validated, err := g.Validate(repair)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &g, append(repaired, validated...), nil
+}
+
+// Validate checks a graph's adjacency lists for out-of-range neighbors,
+// self-loops, duplicate edges and missing reverse edges. If repair is false,
+// the first problem is returned as an error; otherwise the fixable problems
+// are repaired in place and returned as a list
+func (g *Graph) Validate(repair bool) ([]*ValidationError, error) {
+	nVertices := len(g.Vertices)
+	repaired := make([]*ValidationError, 0)
+
+	// report returns an error if the problem can't be repaired
+	report := func(kind ValidationErrorKind, i int, msg string) error {
+		problem := &ValidationError{kind, vertexLine(i), msg}
+		if !repair || !kind.repairable() {
+			return problem
+		}
+		repaired = append(repaired, problem)
+		return nil
+	}
+
+	// check each adjacency list on its own; seen[j] == i+1 if vertex i has
+	// already listed j
+	seen := make([]int, nVertices)
+	hasEdgeWeights := g.Attrs != nil && g.Attrs.EdgeWeights != nil
+	for i := range g.Vertices {
+		v := &g.Vertices[i]
+		adj := v.Adj[:0]
+		var weights []int
+		if hasEdgeWeights {
+			weights = g.Attrs.EdgeWeights[i][:0]
+		}
+		for k, j := range v.Adj {
+			var err error
+			switch {
+			case j < 0 || j >= nVertices:
+				err = report(ERR_OUT_OF_RANGE, i,
+					fmt.Sprintf("neighbor %d out of range", j))
+			case j == i:
+				err = report(ERR_SELF_LOOP, i,
+					fmt.Sprintf("self-loop on vertex %d", i))
+			case seen[j] == i+1:
+				err = report(ERR_DUPLICATE_EDGE, i,
+					fmt.Sprintf("duplicate edge %d-%d", i, j))
+			default:
+				seen[j] = i + 1
+				adj = append(adj, j)
+				if hasEdgeWeights {
+					weights = append(weights, g.Attrs.EdgeWeights[i][k])
+				}
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// only modify the graph if repairing
+		if repair {
+			v.Adj = adj
+			if hasEdgeWeights {
+				g.Attrs.EdgeWeights[i] = weights
+			}
+		}
+	}
+
+	// check symmetry by binary search in sorted copies of the adjacency lists
+	sorted := make([][]int, nVertices)
+	for i := range g.Vertices {
+		sorted[i] = append([]int{}, g.Vertices[i].Adj...)
+		sort.Ints(sorted[i])
+	}
+	missing := make([][3]int, 0)
+	for i := range g.Vertices {
+		for l, j := range g.Vertices[i].Adj {
+			k := sort.SearchInts(sorted[j], i)
+			if k < len(sorted[j]) && sorted[j][k] == i {
+				continue
+			}
+
+			err := report(ERR_MISSING_REVERSE_EDGE, i,
+				fmt.Sprintf("edge %d-%d has no reverse edge", i, j))
+			if err != nil {
+				return nil, err
+			}
+			missing = append(missing, [3]int{j, i, l})
+		}
+	}
+
+	// reverse edges get the same weight as the forward edge
+	for _, edge := range missing {
+		v := &g.Vertices[edge[0]]
+		v.Adj = append(v.Adj, edge[1])
+		if hasEdgeWeights {
+			g.Attrs.EdgeWeights[edge[0]] = append(
+				g.Attrs.EdgeWeights[edge[0]],
+				g.Attrs.EdgeWeights[edge[1]][edge[2]])
+		}
+	}
+
+	return repaired, nil
+}
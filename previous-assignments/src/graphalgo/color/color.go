@@ -0,0 +1,126 @@
+// Package color defines the common interface of the graph colorers in its
+// subpackages (sequential, parallel and distributed), which register
+// themselves by name (see Register) when imported
+package color
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"graph"
+	"runtime"
+	"time"
+)
+
+// ErrMaxColor is returned by colorers that needed more colors than the
+// requested bound; they still finish with a valid coloring, and return its
+// Result along with the error
+var ErrMaxColor = errors.New("color: maxColor exceeded")
+
+// Options configures a coloring; zero values select defaults (see
+// WithDefaults)
+type Options struct {
+	MaxColor int      // bound on the number of colors (0 for none)
+	NThreads int      // number of threads used by parallel colorers
+	Ordering Ordering // vertex order of greedy colorers
+	Seed     uint64   // random seed, e.g., for ORDER_RANDOM
+}
+
+// Result describes a finished coloring
+type Result struct {
+	NumColors int           // number of distinct colors used
+	Rounds    int           // speculative coloring rounds (1 if sequential)
+	Conflicts int           // vertices recolored due to conflicts
+	Elapsed   time.Duration // wall-clock coloring time
+}
+
+// Colorer is a graph coloring algorithm, which colors a graph in place
+// through graph.Interface. Colorers should stop early and return ctx.Err()
+// if the context is canceled (e.g., between rounds). The Result is nil on
+// errors, except on ErrMaxColor
+type Colorer interface {
+	Color(ctx context.Context, g graph.Interface, opts Options) (*Result,
+		error)
+}
+
+// ColorerFunc adapts an ordinary function to the Colorer interface
+type ColorerFunc func(ctx context.Context, g graph.Interface,
+	opts Options) (*Result, error)
+
+// Color calls f(ctx, g, opts)
+func (f ColorerFunc) Color(ctx context.Context, g graph.Interface,
+	opts Options) (*Result, error) {
+
+	return f(ctx, g, opts)
+}
+
+// WithDefaults returns the options with zero values replaced by defaults for
+// a graph: parallel colorers use two threads per CPU, like ColorParallelGM2.
+// There is no default bound on the number of colors, since greedy colorers
+// never need more than Delta+1 colors anyway. Colorers call it first, so it
+// also rejects invalid options, i.e., unknown orderings
+func (opts Options) WithDefaults(g graph.Interface) (Options, error) {
+	if opts.Ordering < 0 || int(opts.Ordering) >= len(orderingNames) {
+		return opts, fmt.Errorf("color: unknown ordering %v", opts.Ordering)
+	}
+	if opts.NThreads <= 0 {
+		opts.NThreads = 2 * runtime.NumCPU()
+	}
+	return opts, nil
+}
+
+// NewResult returns the result of a coloring that started at start, counting
+// the colors used by the graph
+func NewResult(g graph.Interface, start time.Time, rounds,
+	conflicts int) *Result {
+
+	return &Result{
+		NumColors: NumColors(g),
+		Rounds:    rounds,
+		Conflicts: conflicts,
+		Elapsed:   time.Since(start),
+	}
+}
+
+// CheckMaxColor returns ErrMaxColor if a vertex of a graph has a color that
+// isn't below maxColor (0 for no bound). Speculative colorers check the
+// final colors this way, since a vertex may exceed the bound in one round
+// and be recolored below it in a later one
+func CheckMaxColor(g graph.Interface, maxColor int) error {
+	if maxColor <= 0 {
+		return nil
+	}
+	for i := 0; i < g.NumVertices(); i++ {
+		if g.Color(i) >= maxColor {
+			return ErrMaxColor
+		}
+	}
+	return nil
+}
+
+// NumColors returns the number of distinct colors used by a graph
+func NumColors(g graph.Interface) int {
+	used := make(map[int]bool)
+	for i := 0; i < g.NumVertices(); i++ {
+		used[g.Color(i)] = true
+	}
+	return len(used)
+}
+
+// WithCSR runs f on the CSR layout of a graph, for colorers specialized to
+// CSR: a *graph.CSR is used directly, and a *graph.Graph is converted, with
+// the resulting colors copied back into its values (even if f fails, e.g.,
+// with ErrMaxColor)
+func WithCSR(g graph.Interface, f func(c *graph.CSR) error) error {
+	switch g := g.(type) {
+	case *graph.CSR:
+		return f(g)
+	case *graph.Graph:
+		c := graph.NewCSR(g)
+		err := f(c)
+		c.CopyColors(g)
+		return err
+	default:
+		return fmt.Errorf("color: CSR colorer can't color a %T", g)
+	}
+}
@@ -0,0 +1,86 @@
+package distributed
+
+import (
+	"context"
+	"fmt"
+	"graph"
+	"graphalgo/color"
+	"io/ioutil"
+	"log"
+	"time"
+)
+
+// workerColorer colors the subgraph of a worker of a cluster, in lockstep
+// with the other nodes
+type workerColorer func(ctx context.Context, ws *WorkerState,
+	opts color.Options, logger *log.Logger) (*color.Result, error)
+
+// workerColorers are the registered colorers (see color.Register) that can
+// also run as a worker of a cluster, by name; the others don't know about
+// remote neighbors
+var workerColorers = map[string]workerColorer{
+	"distributed": colorWorker,
+}
+
+func init() {
+	color.Register("distributed", color.ColorerFunc(colorLocal))
+}
+
+// RunWorker runs the colorer registered under a name as the worker ws of a
+// cluster, once its connections are set up and its subgraph received, e.g.,
+// so that the client can choose the algorithm from the command line
+func RunWorker(ctx context.Context, name string, ws *WorkerState,
+	opts color.Options, logger *log.Logger) (*color.Result, error) {
+
+	colorer, ok := workerColorers[name]
+	if !ok {
+		if _, err := color.Lookup(name); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("color: colorer %q can't run on a worker",
+			name)
+	}
+	return colorer(ctx, ws, opts, logger)
+}
+
+// colorWorker is ColorDistributed as a workerColorer. The context is only
+// checked before starting, since the worker rounds are synchronized with
+// other nodes, and opts.Ordering is ignored
+func colorWorker(ctx context.Context, ws *WorkerState, opts color.Options,
+	logger *log.Logger) (*color.Result, error) {
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	opts, err := opts.WithDefaults(ws.Subgraph)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	ws.State = STATE_RUNNING
+	err = ColorDistributed(ws, opts.MaxColor, opts.NThreads, logger)
+	ws.State = STATE_FINISHED
+	if err != nil && err != color.ErrMaxColor {
+		return nil, err
+	}
+	return color.NewResult(ws.Subgraph, start, ws.Rounds, ws.Conflicts), err
+}
+
+// colorLocal is ColorDistributed as a color.Colorer, run in-process as the
+// only worker of a cluster with no other nodes (so without any networking),
+// e.g., to compare it with the other colorers
+func colorLocal(ctx context.Context, g graph.Interface,
+	opts color.Options) (*color.Result, error) {
+
+	ws := NewWorkerState()
+	ws.Subgraph = g
+	ws.NodeIndex = 1
+	ws.NodeCount = 2
+	ws.VertexBegin = 0
+	ws.VertexEnd = g.NumVertices()
+	ws.Ranges = []int{0, g.NumVertices()}
+	ws.ConnPool.Register()
+
+	return colorWorker(ctx, ws, opts, log.New(ioutil.Discard, "", 0))
+}
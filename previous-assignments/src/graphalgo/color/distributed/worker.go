@@ -2,60 +2,53 @@ package distributed
 
 import (
 	"encoding/binary"
+	"graphalgo/color"
 	"graphnet"
 	"log"
 	"math"
+	"sync"
 )
 
 // colorSpeculative speculatively colors one group of vertices and notifies
-// other nodes when their neighbors are updated
-func colorSpeculative(u []int, maxColor int, ws *WorkerState) {
+// other nodes when their neighbors are updated. Neighbors in other subgraphs
+// may have any color, which color.ForbiddenColors handles
+func colorSpeculative(u []int, ws *WorkerState) {
 
 	defer ws.ColorWg.Done()
 	iBegin, iEnd := ws.VertexBegin, ws.VertexEnd
-	var color int
-	neighborColors := make([]bool, maxColor)
-	neighborColorsDefault := make([]bool, maxColor)
+	var forbidden color.ForbiddenColors
 	sg := ws.Subgraph
 	buf := make([]byte, 8)
 
 	// loop over vertices for this thread
 	for _, i := range u {
-		v := &sg.Vertices[i]
-
-		copy(neighborColors, neighborColorsDefault)
+		forbidden.Reset(sg.Degree(i))
 
 		// speculatively color
-		for _, j := range v.Adj {
+		for k := 0; k < sg.Degree(i); k++ {
+			j := sg.Neighbor(i, k)
 			if j < iBegin || j >= iEnd {
 				ws.StoredMutex.Lock()
-				color = ws.Stored[j]
+				forbidden.Forbid(ws.Stored[j])
 				ws.StoredMutex.Unlock()
 			} else {
-				color = sg.Vertices[j-iBegin].Value
+				forbidden.Forbid(sg.Color(j - iBegin))
 			}
-
-			neighborColors[color] = true
 		}
 
 		// find first valid color
-		for j := range neighborColors {
-			if !neighborColors[j] {
-				v.Value = j
-
-				// notify all larger neighbors in different subgraphs
-				for _, k := range v.Adj {
-					if k >= iEnd {
-						binary.LittleEndian.PutUint32(buf[:4], uint32(j))
-						binary.LittleEndian.PutUint32(buf[4:], uint32(i+iBegin))
-						// TODO: later work on buffering
-						ws.ConnPool.Conns[1+(k/len(sg.Vertices))].
-							WriteBytes(graphnet.MSG_VERTEX_INFO, buf,
-								true)
-					}
-				}
-
-				break
+		j := forbidden.First()
+		sg.SetColor(i, j)
+
+		// notify all larger neighbors in different subgraphs
+		for k := 0; k < sg.Degree(i); k++ {
+			l := sg.Neighbor(i, k)
+			if l >= iEnd {
+				binary.LittleEndian.PutUint32(buf[:4], uint32(j))
+				binary.LittleEndian.PutUint32(buf[4:], uint32(i+iBegin))
+				// TODO: later work on buffering
+				ws.ConnPool.Conns[ws.Owner(l)].
+					WriteBytes(graphnet.MSG_VERTEX_INFO, buf, true)
 			}
 		}
 	}
@@ -63,7 +56,7 @@ func colorSpeculative(u []int, maxColor int, ws *WorkerState) {
 
 // resolveConflicts simply marks nodes that have conflicts to be recolored
 // in the next round; doesn't require any inter-node communication
-func resolveConflicts(u []int, ws *WorkerState, r *[]int) {
+func resolveConflicts(u []int, ws *WorkerState, r *[]int, m *sync.Mutex) {
 
 	defer ws.DetectWg.Done()
 
@@ -72,11 +65,10 @@ func resolveConflicts(u []int, ws *WorkerState, r *[]int) {
 	sg := ws.Subgraph
 
 	for _, i := range u {
-		v := &sg.Vertices[i]
-
-		for _, j := range v.Adj {
+		for k := 0; k < sg.Degree(i); k++ {
+			j := sg.Neighbor(i, k)
 			if j >= iBegin && j < iEnd {
-				color = sg.Vertices[j-iBegin].Value
+				color = sg.Color(j - iBegin)
 			} else {
 				ws.StoredMutex.Lock()
 				color = ws.Stored[j]
@@ -84,24 +76,59 @@ func resolveConflicts(u []int, ws *WorkerState, r *[]int) {
 			}
 
 			// if conflict detected, set larger-indexed node to be recolored
-			if color == v.Value && i+iBegin > j {
+			if color == sg.Color(i) && i+iBegin > j {
+				m.Lock()
 				*r = append(*r, i)
+				m.Unlock()
+				break
 			}
 		}
 	}
 }
 
+// detectConflicts runs resolveConflicts on the vertices u in nThreads
+// threads, appending the conflicting ones to r, and returns them as the next
+// u along with the buffer of u for the next r; the buffers are swapped rather
+// than reallocated, but r never shares the backing array of u, which is read
+// while r is appended to
+func detectConflicts(u, r []int, ws *WorkerState, nThreads int,
+	m *sync.Mutex) ([]int, []int) {
+
+	nVertices := len(u)
+	verticesPerThread := int(math.Ceil(float64(nVertices) /
+		float64(nThreads)))
+	ws.DetectWg.Add(nThreads)
+	for i := 0; i < nThreads; i++ {
+		start := i * verticesPerThread
+		end := (i + 1) * verticesPerThread
+		if start > nVertices {
+			start = nVertices
+		}
+		if end > nVertices {
+			end = nVertices
+		}
+
+		go resolveConflicts(u[start:end], ws, &r, m)
+	}
+	ws.DetectWg.Wait()
+
+	return r, u[:0]
+}
+
 // ColorDistributed is the main driver for the distributed coloring algorithm
-// on the slave node, and is called after all the connections are set up
+// on the slave node, and is called after all the connections are set up.
+// maxColor is an optional bound (0 for none): if more colors are needed, the
+// worker still finishes its rounds in lockstep with the other nodes (so that
+// they don't hang) and then returns color.ErrMaxColor
 func ColorDistributed(ws *WorkerState, maxColor, nThreads int,
-	logger *log.Logger) {
+	logger *log.Logger) error {
 
-	sg := ws.Subgraph
 	buf := make([]byte, 8)
+	var m sync.Mutex
 
-	// initialize U to be all of the vertices in sg
-	u := make([]int, len(sg.Vertices))
-	for i := 0; i < len(sg.Vertices); i++ {
+	// initialize U to be all of the vertices in the subgraph
+	u := make([]int, ws.Subgraph.NumVertices())
+	for i := range u {
 		u[i] = i
 	}
 	r := make([]int, 0)
@@ -121,6 +148,7 @@ func ColorDistributed(ws *WorkerState, maxColor, nThreads int,
 		ws.ColorWg.Add(ws.NodeCount - 2)
 		ws.ColorWgLock.Unlock()
 
+		ws.Rounds++
 		logger.Printf("Beginning new round: %d vertices to be colored\n",
 			len(u))
 
@@ -143,7 +171,7 @@ func ColorDistributed(ws *WorkerState, maxColor, nThreads int,
 				end = nVertices
 			}
 
-			go colorSpeculative(u[start:end], maxColor, ws)
+			go colorSpeculative(u[start:end], ws)
 		}
 
 		// flush all write buffers
@@ -160,28 +188,16 @@ func ColorDistributed(ws *WorkerState, maxColor, nThreads int,
 		logger.Printf("Beginning conflict resolution stage\n")
 
 		// for each boundary vertex, check for conflicts (in parallel)
-		// add conflicting nodes to R
-		ws.DetectWg.Add(nThreads)
-		for i := 0; i < nThreads; i++ {
-			start := i * verticesPerThread
-			end := (i + 1) * verticesPerThread
-			if start > nVertices {
-				start = nVertices
-			}
-			if end > nVertices {
-				end = nVertices
-			}
-
-			go resolveConflicts(u[start:end], ws, &r)
-		}
-		ws.DetectWg.Wait()
-
-		// set U to R
-		u = r
-		r = u[:0]
+		// add conflicting nodes to R, and set U to R
+		u, r = detectConflicts(u, r, ws, nThreads, &m)
+		ws.Conflicts += len(u)
 	}
 
 	// when done coloring, notify all nodes
 	buf[0] = byte(ws.NodeIndex)
 	ws.ConnPool.Broadcast(graphnet.MSG_NODE_FINISHED, buf[:1])
+
+	// speculative colors may exceed maxColor in one round and be recolored
+	// below it in a later one, so the bound is checked on the final colors
+	return color.CheckMaxColor(ws.Subgraph, maxColor)
 }
@@ -0,0 +1,67 @@
+package distributed
+
+import (
+	"graph"
+	"sort"
+	"sync"
+	"testing"
+)
+
+// checkMarked is a helper for TestResolveConflicts, which checks that the
+// vertices marked for recoloring are the expected ones, each exactly once
+func checkMarked(t *testing.T, round int, r, expected []int) {
+	marked := append([]int(nil), r...)
+	sort.Ints(marked)
+	if len(marked) != len(expected) {
+		t.Fatalf("Round %d: %d vertices marked for recoloring, expected %d",
+			round, len(marked), len(expected))
+	}
+	for k, i := range marked {
+		if i != expected[k] {
+			t.Fatalf("Round %d: vertex %d marked for recoloring, expected %d",
+				round, i, expected[k])
+		}
+	}
+}
+
+// TestResolveConflicts checks that concurrent conflict detection marks each
+// conflicting vertex exactly once, also in a second round reusing the
+// buffers of the first; run with -race to check that the shared recolor list
+// is guarded (speculative coloring itself reads neighbor colors while
+// they're written by design, so only this phase is checked)
+func TestResolveConflicts(t *testing.T) {
+	const N, nThreads = 200, 8
+
+	// on a monochromatic complete graph, every vertex but the first conflicts
+	// with a smaller neighbor
+	g := graph.NewCompleteGraph(N)
+	for i := 0; i < N; i++ {
+		g.SetColor(i, 0)
+	}
+	ws := NewWorkerState()
+	ws.Subgraph = &g
+	ws.VertexBegin, ws.VertexEnd = 0, N
+
+	u := make([]int, N)
+	for i := range u {
+		u[i] = i
+	}
+	r := make([]int, 0)
+	var m sync.Mutex
+
+	u, r = detectConflicts(u, r, ws, nThreads, &m)
+	expected := make([]int, 0)
+	for i := 1; i < N; i++ {
+		expected = append(expected, i)
+	}
+	checkMarked(t, 1, u, expected)
+
+	// recoloring the even vertices but the first, all of them but 2 still
+	// conflict with a smaller neighbor
+	for i := 2; i < N; i += 2 {
+		g.SetColor(i, 1)
+	}
+	u, _ = detectConflicts(u, r, ws, nThreads, &m)
+	expected = append(expected[:1], expected[2:]...)
+	checkMarked(t, 2, u, expected)
+}
@@ -3,24 +3,28 @@ package distributed
 import (
 	"graph"
 	"graphnet"
+	"sort"
 	"sync"
 )
 
 // WorkerState holds the algorithm state for a worker node
 type WorkerState struct {
-	Subgraph    *graph.Graph
-	NodeIndex   int            // node index in NodeConnPool
-	NodeCount   int            // total number of nodes (including server)
-	VertexBegin int            // start of vertex range
-	VertexEnd   int            // end of vertex range
-	Stored      map[int]int    // received neighbor vertex values
-	StoredMutex sync.Mutex     // mutex for the above (TODO: make R/W lock?)
-	StartWg     sync.WaitGroup // WaitGroup for starting the round
-	ColorWg     sync.WaitGroup // WaitGroup for speculative coloring
-	DetectWg    sync.WaitGroup // WaitGroup for conflict detection
-	ColorWgLock sync.Mutex     // to protect the consistency of colorWg
+	Subgraph    graph.Interface // local vertices, with global neighbors
+	NodeIndex   int             // node index in NodeConnPool
+	NodeCount   int             // total number of nodes (including server)
+	VertexBegin int             // start of vertex range
+	VertexEnd   int             // end of vertex range
+	Ranges      []int           // vertex range starts of all nodes, and end
+	Stored      map[int]int     // received neighbor vertex values
+	StoredMutex sync.Mutex      // mutex for the above (TODO: make R/W lock?)
+	StartWg     sync.WaitGroup  // WaitGroup for starting the round
+	ColorWg     sync.WaitGroup  // WaitGroup for speculative coloring
+	DetectWg    sync.WaitGroup  // WaitGroup for conflict detection
+	ColorWgLock sync.Mutex      // to protect the consistency of colorWg
 	ConnPool    graphnet.NodeConnPool
 	State       AlgoState
+	Rounds      int // speculative coloring rounds so far
+	Conflicts   int // local vertices recolored due to conflicts so far
 }
 
 // NewWorkerState initializes a new WorkerState
@@ -33,6 +37,12 @@ func NewWorkerState() *WorkerState {
 	return &ws
 }
 
+// Owner returns the node index of the worker whose vertex range contains a
+// (global) vertex; ranges may differ in size, e.g., if balanced by weight
+func (ws *WorkerState) Owner(vertex int) int {
+	return sort.SearchInts(ws.Ranges, vertex+1)
+}
+
 // AlgoState is used to determine the current state of the algorithm (e.g.,
 // for heartbeat purposes and to have clean cleanup procedures)
 type AlgoState int
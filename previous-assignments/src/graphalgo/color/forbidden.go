@@ -0,0 +1,47 @@
+package color
+
+// ForbiddenColors tracks the colors forbidden by a vertex's neighbors, for
+// greedy (first-fit) colorers. Rather than clearing a []bool for every
+// vertex, which costs O(maxColor) even for vertices of small degree, each
+// color is stamped with the vertex it was last forbidden for, and a color is
+// forbidden only if its stamp is the current one. A vertex of degree d always
+// gets one of the colors 0 to d, so the marker array only grows to Delta+1,
+// larger colors are ignored, and each vertex costs O(d). The zero value is
+// ready to use; a ForbiddenColors must not be shared between goroutines
+type ForbiddenColors struct {
+	stamps []uint32 // stamp of the last vertex forbidding each color
+	stamp  uint32   // stamp of the current vertex
+}
+
+// Reset starts a new vertex of a given degree, with no forbidden colors
+func (f *ForbiddenColors) Reset(degree int) {
+	if degree >= len(f.stamps) {
+		f.stamps = make([]uint32, 2*degree+1)
+		f.stamp = 0
+	}
+
+	// on wraparound, old stamps could match again, so clear them
+	f.stamp++
+	if f.stamp == 0 {
+		for c := range f.stamps {
+			f.stamps[c] = 0
+		}
+		f.stamp = 1
+	}
+}
+
+// Forbid marks a color as used by a neighbor of the current vertex
+func (f *ForbiddenColors) Forbid(c int) {
+	if c >= 0 && c < len(f.stamps) {
+		f.stamps[c] = f.stamp
+	}
+}
+
+// First returns the smallest color not forbidden for the current vertex
+func (f *ForbiddenColors) First() int {
+	c := 0
+	for f.stamps[c] == f.stamp {
+		c++
+	}
+	return c
+}
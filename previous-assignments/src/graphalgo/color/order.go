@@ -0,0 +1,173 @@
+package color
+
+import (
+	"fmt"
+	"graph"
+	"graph/stats"
+	"math/rand"
+)
+
+// Ordering is a strategy for the order in which greedy colorers color
+// vertices; the color count of greedy coloring depends heavily on it, e.g.,
+// on skewed degree distributions
+type Ordering int
+
+const (
+	// ORDER_NATURAL colors vertices in index order
+	ORDER_NATURAL Ordering = iota
+
+	// ORDER_LARGEST_FIRST colors vertices by decreasing degree (Welsh and
+	// Powell)
+	ORDER_LARGEST_FIRST Ordering = iota
+
+	// ORDER_SMALLEST_LAST colors vertices in reverse degeneracy order (Matula
+	// and Beck), using at most Degeneracy+1 colors
+	ORDER_SMALLEST_LAST Ordering = iota
+
+	// ORDER_INCIDENCE_DEGREE colors next the vertex with the most neighbors
+	// already colored
+	ORDER_INCIDENCE_DEGREE Ordering = iota
+
+	// ORDER_RANDOM colors vertices in a random order (see Options.Seed)
+	ORDER_RANDOM Ordering = iota
+)
+
+// orderingNames are the names of the orderings, e.g., for command-line flags
+var orderingNames = []string{"natural", "largest-first", "smallest-last",
+	"incidence-degree", "random"}
+
+// Orderings returns all orderings
+func Orderings() []Ordering {
+	orderings := make([]Ordering, len(orderingNames))
+	for i := range orderings {
+		orderings[i] = Ordering(i)
+	}
+	return orderings
+}
+
+// String returns the name of an ordering
+func (o Ordering) String() string {
+	if o < 0 || int(o) >= len(orderingNames) {
+		return fmt.Sprintf("Ordering(%d)", int(o))
+	}
+	return orderingNames[o]
+}
+
+// ParseOrdering returns the ordering with a given name (see String)
+func ParseOrdering(name string) (Ordering, error) {
+	for i, orderingName := range orderingNames {
+		if name == orderingName {
+			return Ordering(i), nil
+		}
+	}
+	return 0, fmt.Errorf("color: unknown ordering %q (have %v)", name,
+		orderingNames)
+}
+
+// Order returns the vertices of a graph in the order given by an ordering;
+// the seed is only used by ORDER_RANDOM. It panics on an unknown ordering,
+// which colorers reject beforehand (see Options.WithDefaults)
+func Order(g graph.Interface, ordering Ordering, seed uint64) []int {
+	nVertices := g.NumVertices()
+
+	switch ordering {
+	case ORDER_NATURAL:
+		order := make([]int, nVertices)
+		for i := range order {
+			order[i] = i
+		}
+		return order
+
+	case ORDER_LARGEST_FIRST:
+		return largestFirstOrder(g)
+
+	case ORDER_SMALLEST_LAST:
+		order := stats.DegeneracyOrdering(g)
+		for i, j := 0, len(order)-1; i < j; i, j = i+1, j-1 {
+			order[i], order[j] = order[j], order[i]
+		}
+		return order
+
+	case ORDER_INCIDENCE_DEGREE:
+		return incidenceDegreeOrder(g)
+
+	case ORDER_RANDOM:
+		return rand.New(rand.NewSource(int64(seed))).Perm(nVertices)
+
+	default:
+		panic("Invalid ordering")
+	}
+}
+
+// largestFirstOrder sorts the vertices by decreasing degree (and increasing
+// index for equal degrees) with a counting sort, in O(n+Delta) time
+func largestFirstOrder(g graph.Interface) []int {
+	nVertices := g.NumVertices()
+	maxDegree := stats.MaxDegree(g)
+
+	// start[d] is the index of the first vertex of degree d in the order
+	start := make([]int, maxDegree+2)
+	for i := 0; i < nVertices; i++ {
+		start[maxDegree-g.Degree(i)+1]++
+	}
+	for d := 1; d < len(start); d++ {
+		start[d] += start[d-1]
+	}
+
+	order := make([]int, nVertices)
+	for i := 0; i < nVertices; i++ {
+		d := maxDegree - g.Degree(i)
+		order[start[d]] = i
+		start[d]++
+	}
+	return order
+}
+
+// incidenceDegreeOrder repeatedly picks the vertex with the most neighbors
+// already picked (and the lowest index among those, initially). Vertices are
+// kept in buckets by incidence degree; a vertex moving up a bucket is pushed
+// again and its stale entry skipped later, so this takes O(n+m) time
+func incidenceDegreeOrder(g graph.Interface) []int {
+	nVertices := g.NumVertices()
+	incidence := make([]int, nVertices)
+	picked := make([]bool, nVertices)
+
+	buckets := [][]int{make([]int, nVertices)}
+	for i := range buckets[0] {
+		buckets[0][i] = nVertices - 1 - i
+	}
+	max := 0
+
+	order := make([]int, 0, nVertices)
+	for len(order) < nVertices {
+		// pop the top of the highest nonempty bucket
+		for len(buckets[max]) == 0 {
+			max--
+		}
+		top := len(buckets[max]) - 1
+		i := buckets[max][top]
+		buckets[max] = buckets[max][:top]
+		if picked[i] || incidence[i] != max {
+			continue
+		}
+
+		picked[i] = true
+		order = append(order, i)
+		for k := 0; k < g.Degree(i); k++ {
+			j := g.Neighbor(i, k)
+			if picked[j] {
+				continue
+			}
+			incidence[j]++
+			if incidence[j] == len(buckets) {
+				buckets = append(buckets, make([]int, 0))
+			}
+			buckets[incidence[j]] = append(buckets[incidence[j]], j)
+			if incidence[j] > max {
+				max = incidence[j]
+			}
+		}
+	}
+
+	return order
+}
@@ -0,0 +1,123 @@
+package parallel
+
+import (
+	"context"
+	"graph"
+	"graphalgo/color"
+	"time"
+)
+
+func init() {
+	color.Register("gm", color.ColorerFunc(colorGM))
+	color.Register("gm2", color.ColorerFunc(colorGM2))
+	color.Register("gm-csr", color.ColorerFunc(colorGMCSR))
+	color.Register("gm2-csr", color.ColorerFunc(colorGM2CSR))
+	color.Register("jp", jpColorer(JP_RANDOM))
+	color.Register("jp-ldf", jpColorer(JP_LARGEST_DEGREE_FIRST))
+}
+
+// colorGM is ColorParallelGM as a color.Colorer
+func colorGM(ctx context.Context, g graph.Interface,
+	opts color.Options) (*color.Result, error) {
+
+	opts, err := opts.WithDefaults(g)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	order := color.Order(g, opts.Ordering, opts.Seed)
+	rounds, conflicts, err := colorParallelGM(ctx, g, order, opts.MaxColor)
+	if err != nil && err != color.ErrMaxColor {
+		return nil, err
+	}
+	return color.NewResult(g, start, rounds, conflicts), err
+}
+
+// colorGM2 is ColorParallelGM2 as a color.Colorer, using opts.NThreads
+func colorGM2(ctx context.Context, g graph.Interface,
+	opts color.Options) (*color.Result, error) {
+
+	opts, err := opts.WithDefaults(g)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	order := color.Order(g, opts.Ordering, opts.Seed)
+	rounds, conflicts, err := colorParallelGM2(ctx, g, order, opts.MaxColor,
+		opts.NThreads)
+	if err != nil && err != color.ErrMaxColor {
+		return nil, err
+	}
+	return color.NewResult(g, start, rounds, conflicts), err
+}
+
+// colorGMCSR is ColorParallelGMCSR as a color.Colorer
+func colorGMCSR(ctx context.Context, g graph.Interface,
+	opts color.Options) (*color.Result, error) {
+
+	opts, err := opts.WithDefaults(g)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	order := color.Order(g, opts.Ordering, opts.Seed)
+	var rounds, conflicts int
+	err = color.WithCSR(g, func(c *graph.CSR) (err error) {
+		rounds, conflicts, err = colorParallelGMCSR(ctx, c, order,
+			opts.MaxColor)
+		return err
+	})
+	if err != nil && err != color.ErrMaxColor {
+		return nil, err
+	}
+	return color.NewResult(g, start, rounds, conflicts), err
+}
+
+// colorGM2CSR is ColorParallelGM2CSR as a color.Colorer, using
+// opts.NThreads
+func colorGM2CSR(ctx context.Context, g graph.Interface,
+	opts color.Options) (*color.Result, error) {
+
+	opts, err := opts.WithDefaults(g)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	order := color.Order(g, opts.Ordering, opts.Seed)
+	var rounds, conflicts int
+	err = color.WithCSR(g, func(c *graph.CSR) (err error) {
+		rounds, conflicts, err = colorParallelGM2CSR(ctx, c, order,
+			opts.MaxColor, opts.NThreads)
+		return err
+	})
+	if err != nil && err != color.ErrMaxColor {
+		return nil, err
+	}
+	return color.NewResult(g, start, rounds, conflicts), err
+}
+
+// jpColorer returns ColorJonesPlassmann with a given priority as a
+// color.Colorer, using opts.NThreads and opts.Seed; opts.Ordering is ignored,
+// since the priorities define the order
+func jpColorer(priority JPPriority) color.Colorer {
+	return color.ColorerFunc(func(ctx context.Context, g graph.Interface,
+		opts color.Options) (*color.Result, error) {
+
+		opts, err := opts.WithDefaults(g)
+		if err != nil {
+			return nil, err
+		}
+
+		start := time.Now()
+		rounds, err := colorJonesPlassmann(ctx, g, priority, opts.MaxColor,
+			opts.NThreads, opts.Seed)
+		if err != nil && err != color.ErrMaxColor {
+			return nil, err
+		}
+		return color.NewResult(g, start, rounds, 0), err
+	})
+}
@@ -2,41 +2,44 @@
 package parallel
 
 import (
+	"context"
 	"graph"
+	"graphalgo/color"
 	"sync"
 )
 
+// forbiddenPool recycles the forbidden-color markers of the one-goroutine-
+// per-node colorers, which would otherwise allocate one per node per round
+var forbiddenPool = sync.Pool{
+	New: func() interface{} { return new(color.ForbiddenColors) },
+}
+
 // colorNodeParallel speculatively colors a single node, not paying attention
 // to data consistency (this will be detected in conflict resolution)
-func colorNodeParallel(g *graph.Graph, i int, wg *sync.WaitGroup,
-	maxColor int) {
+func colorNodeParallel(g graph.Interface, i int, wg *sync.WaitGroup) {
 
 	defer wg.Done()
-	v := &g.Vertices[i]
 
-	neighborColors := make([]bool, maxColor)
+	forbidden := forbiddenPool.Get().(*color.ForbiddenColors)
+	defer forbiddenPool.Put(forbidden)
 
-	for _, j := range v.Adj {
-		neighborColors[g.Vertices[j].Value] = true
+	forbidden.Reset(g.Degree(i))
+	for k := 0; k < g.Degree(i); k++ {
+		forbidden.Forbid(g.Color(g.Neighbor(i, k)))
 	}
 
-	for i := 0; i < maxColor; i++ {
-		if !neighborColors[i] {
-			v.Value = i
-			return
-		}
-	}
-	panic("maxColor exceeded")
+	j := forbidden.First()
+	g.SetColor(i, j)
 }
 
-func checkNodeConflictsParallel(g *graph.Graph, i int,
+func checkNodeConflictsParallel(g graph.Interface, i int,
 	wg *sync.WaitGroup, ch chan int) {
 
 	defer wg.Done()
-	v := &g.Vertices[i]
 
-	for _, j := range v.Adj {
-		if g.Vertices[j].Value == v.Value && j > i {
+	for k := 0; k < g.Degree(i); k++ {
+		j := g.Neighbor(i, k)
+		if g.Color(j) == g.Color(i) && j > i {
 			ch <- i
 			return
 		}
@@ -44,23 +47,50 @@ func checkNodeConflictsParallel(g *graph.Graph, i int,
 }
 
 // ColorParallelGM is the driver for the parallel coloring scheme following the
-// Gebremedhin-Manne color outlined in https://www.osti.gov/biblio/1246285
-func ColorParallelGM(g *graph.Graph, maxColor int) {
+// Gebremedhin-Manne color outlined in https://www.osti.gov/biblio/1246285;
+// maxColor is an optional bound, as in ColorSequential
+func ColorParallelGM(g graph.Interface, maxColor int) error {
+	_, _, err := colorParallelGM(context.Background(), g, nil, maxColor)
+	return err
+}
+
+// colorParallelGM is ColorParallelGM, returning the number of rounds and of
+// recolored conflicting vertices; it stops between rounds if the context is
+// canceled. The vertices are first colored in the given order (see
+// color.Order), or in index order if order is nil
+func colorParallelGM(ctx context.Context, g graph.Interface, order []int,
+	maxColor int) (int, int, error) {
+
 	var wg sync.WaitGroup
+	rounds, conflicts := 0, 0
 
 	// set u to be a list of all of the nodes in the graph; it has
 	// to be a list of node pointers so we actually update the graph
-	u := make([]int, len(g.Vertices))
-	for i := range g.Vertices {
+	u := make([]int, g.NumVertices())
+	for i := range u {
 		u[i] = i
 	}
+	if order != nil {
+		copy(u, order)
+	}
+
+	// uncolor all nodes, so that only the colors of neighbors colored
+	// earlier (in order, or by other threads) are forbidden
+	for i := range u {
+		g.SetColor(i, -1)
+	}
 
 	// repeat process until run out of nodes to recolor
 	for len(u) > 0 {
+		if err := ctx.Err(); err != nil {
+			return rounds, conflicts, err
+		}
+		rounds++
+
 		// speculative coloring
 		wg.Add(len(u))
 		for i := range u {
-			go colorNodeParallel(g, u[i], &wg, maxColor)
+			go colorNodeParallel(g, u[i], &wg)
 		}
 		wg.Wait()
 
@@ -86,5 +116,8 @@ func ColorParallelGM(g *graph.Graph, maxColor int) {
 		for node := range ch {
 			u = append(u, node)
 		}
+		conflicts += len(u)
 	}
+
+	return rounds, conflicts, color.CheckMaxColor(g, maxColor)
 }
@@ -2,47 +2,41 @@
 package parallel
 
 import (
+	"context"
 	"graph"
+	"graphalgo/color"
 	"runtime"
 	"sync"
 )
 
 // colorNodeParallel speculatively colors a single node, not paying attention
 // to data consistency (this will be detected in conflict resolution)
-func colorNodeParallel2(g *graph.Graph, u []int, maxColor int,
-	wg *sync.WaitGroup) {
+func colorNodeParallel2(g graph.Interface, u []int, wg *sync.WaitGroup) {
 
 	defer wg.Done()
 
-	neighborColors := make([]bool, maxColor)
-	neighborColorsZeros := make([]bool, maxColor)
+	var forbidden color.ForbiddenColors
 
 	for _, i := range u {
-		copy(neighborColors[:], neighborColorsZeros[:])
-
-		v := &g.Vertices[i]
-		for _, j := range v.Adj {
-			neighborColors[g.Vertices[j].Value] = true
+		forbidden.Reset(g.Degree(i))
+		for k := 0; k < g.Degree(i); k++ {
+			forbidden.Forbid(g.Color(g.Neighbor(i, k)))
 		}
 
-		for j := 0; j < maxColor; j++ {
-			if !neighborColors[j] {
-				v.Value = j
-				break
-			}
-		}
+		j := forbidden.First()
+		g.SetColor(i, j)
 	}
 }
 
-func checkNodeConflictsParallel2(g *graph.Graph, u []int, wg *sync.WaitGroup,
-	r *[]int, m *sync.Mutex) {
+func checkNodeConflictsParallel2(g graph.Interface, u []int,
+	wg *sync.WaitGroup, r *[]int, m *sync.Mutex) {
 
 	defer wg.Done()
 
 	for _, i := range u {
-		v := &g.Vertices[i]
-		for _, j := range v.Adj {
-			if g.Vertices[j].Value == v.Value && j > i {
+		for k := 0; k < g.Degree(i); k++ {
+			j := g.Neighbor(i, k)
+			if g.Color(j) == g.Color(i) && j > i {
 				m.Lock()
 				*r = append(*r, i)
 				m.Unlock()
@@ -54,17 +48,38 @@ func checkNodeConflictsParallel2(g *graph.Graph, u []int, wg *sync.WaitGroup,
 
 // ColorParallelGM is the driver for the parallel coloring scheme following the
 // Gebremedhin-Manne color outlined in https://www.osti.gov/biblio/1246285
-func ColorParallelGM2(g *graph.Graph, maxColor int) {
+func ColorParallelGM2(g graph.Interface, maxColor int) error {
+	_, _, err := colorParallelGM2(context.Background(), g, nil, maxColor,
+		2*runtime.NumCPU())
+	return err
+}
+
+// colorParallelGM2 is ColorParallelGM2 with a given number of threads,
+// returning the number of rounds and of recolored conflicting vertices; it
+// stops between rounds if the context is canceled. Each thread first colors
+// its share of the vertices in the given order, as in colorParallelGM
+func colorParallelGM2(ctx context.Context, g graph.Interface, order []int,
+	maxColor, nThreads int) (int, int, error) {
+
 	var wg sync.WaitGroup
 	var m sync.Mutex
-	nThreads := 2 * runtime.NumCPU()
+	rounds, conflicts := 0, 0
 
 	// set u to be a list of all of the nodes in the graph; it has
 	// to be a list of node pointers so we actually update the graph
-	u := make([]int, len(g.Vertices))
-	for i := range g.Vertices {
+	u := make([]int, g.NumVertices())
+	for i := range u {
 		u[i] = i
 	}
+	if order != nil {
+		copy(u, order)
+	}
+
+	// uncolor all nodes, so that only the colors of neighbors colored
+	// earlier (in order, or by other threads) are forbidden
+	for i := range u {
+		g.SetColor(i, -1)
+	}
 
 	// create secondary buffer
 	r := make([]int, 0, len(u)/10)
@@ -79,6 +94,10 @@ func ColorParallelGM2(g *graph.Graph, maxColor int) {
 
 	// repeat process until run out of nodes to recolor
 	for len(u) > 0 {
+		if err := ctx.Err(); err != nil {
+			return rounds, conflicts, err
+		}
+		rounds++
 		nVertices := len(u)
 
 		nodesPerThread := nVertices / nThreads
@@ -91,7 +110,7 @@ func ColorParallelGM2(g *graph.Graph, maxColor int) {
 		for i := 0; i < nThreads; i++ {
 			start := min(i * nodesPerThread, nVertices)
 			end := min(start + nodesPerThread, nVertices)
-			go colorNodeParallel2(g, u[start:end], maxColor, &wg)
+			go colorNodeParallel2(g, u[start:end], &wg)
 		}
 		wg.Wait()
 
@@ -110,5 +129,8 @@ func ColorParallelGM2(g *graph.Graph, maxColor int) {
 		tmp := u
 		u = r
 		r = tmp[:0]
+		conflicts += len(u)
 	}
+
+	return rounds, conflicts, color.CheckMaxColor(g, maxColor)
 }
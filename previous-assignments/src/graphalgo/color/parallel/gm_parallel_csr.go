@@ -0,0 +1,224 @@
+// This implementation runs both Gebremedhin-Manne variants (see
+// gm_parallel.go and gm_parallel2.go) specialized to the CSR graph layout,
+// avoiding the overhead of graph.Interface
+package parallel
+
+import (
+	"context"
+	"graph"
+	"graphalgo/color"
+	"runtime"
+	"sync"
+)
+
+// colorNodeParallelCSR speculatively colors a single node of a CSR, not
+// paying attention to data consistency
+func colorNodeParallelCSR(c *graph.CSR, i int, wg *sync.WaitGroup) {
+
+	defer wg.Done()
+
+	forbidden := forbiddenPool.Get().(*color.ForbiddenColors)
+	defer forbiddenPool.Put(forbidden)
+
+	neighbors := c.Neighbors(i)
+	forbidden.Reset(len(neighbors))
+	for _, j := range neighbors {
+		forbidden.Forbid(int(c.Colors[j]))
+	}
+
+	j := forbidden.First()
+	c.Colors[i] = int32(j)
+}
+
+func checkNodeConflictsParallelCSR(c *graph.CSR, i int,
+	wg *sync.WaitGroup, ch chan int) {
+
+	defer wg.Done()
+
+	for _, j := range c.Neighbors(i) {
+		if c.Colors[j] == c.Colors[i] && int(j) > i {
+			ch <- i
+			return
+		}
+	}
+}
+
+// ColorParallelGMCSR is ColorParallelGM (one goroutine per node) on the CSR
+// graph layout
+func ColorParallelGMCSR(c *graph.CSR, maxColor int) error {
+	_, _, err := colorParallelGMCSR(context.Background(), c, nil, maxColor)
+	return err
+}
+
+// colorParallelGMCSR is colorParallelGM on the CSR graph layout
+func colorParallelGMCSR(ctx context.Context, c *graph.CSR, order []int,
+	maxColor int) (int, int, error) {
+
+	var wg sync.WaitGroup
+	rounds, conflicts := 0, 0
+
+	u := make([]int, c.NumVertices())
+	for i := range u {
+		u[i] = i
+	}
+	if order != nil {
+		copy(u, order)
+	}
+
+	// uncolor all nodes, as in colorParallelGM
+	for i := range c.Colors {
+		c.Colors[i] = -1
+	}
+
+	// repeat process until run out of nodes to recolor
+	for len(u) > 0 {
+		if err := ctx.Err(); err != nil {
+			return rounds, conflicts, err
+		}
+		rounds++
+
+		// speculative coloring
+		wg.Add(len(u))
+		for i := range u {
+			go colorNodeParallelCSR(c, u[i], &wg)
+		}
+		wg.Wait()
+
+		// conflict resolution: generate a list of nodes to recolor
+		wg.Add(len(u))
+		ch := make(chan int, 64)
+		for i := range u {
+			go checkNodeConflictsParallelCSR(c, u[i], &wg, ch)
+		}
+
+		go func() {
+			wg.Wait()
+			close(ch)
+		}()
+
+		u = make([]int, 0)
+		for node := range ch {
+			u = append(u, node)
+		}
+		conflicts += len(u)
+	}
+
+	return rounds, conflicts, color.CheckMaxColor(c, maxColor)
+}
+
+// colorNodeParallel2CSR speculatively colors a group of nodes of a CSR
+func colorNodeParallel2CSR(c *graph.CSR, u []int, wg *sync.WaitGroup) {
+
+	defer wg.Done()
+
+	var forbidden color.ForbiddenColors
+
+	for _, i := range u {
+		neighbors := c.Neighbors(i)
+		forbidden.Reset(len(neighbors))
+		for _, j := range neighbors {
+			forbidden.Forbid(int(c.Colors[j]))
+		}
+
+		j := forbidden.First()
+		c.Colors[i] = int32(j)
+	}
+}
+
+func checkNodeConflictsParallel2CSR(c *graph.CSR, u []int,
+	wg *sync.WaitGroup, r *[]int, m *sync.Mutex) {
+
+	defer wg.Done()
+
+	for _, i := range u {
+		for _, j := range c.Neighbors(i) {
+			if c.Colors[j] == c.Colors[i] && int(j) > i {
+				m.Lock()
+				*r = append(*r, i)
+				m.Unlock()
+				break
+			}
+		}
+	}
+}
+
+// ColorParallelGM2CSR is ColorParallelGM2 (nodes grouped into a fixed number
+// of goroutines) on the CSR graph layout
+func ColorParallelGM2CSR(c *graph.CSR, maxColor int) error {
+	_, _, err := colorParallelGM2CSR(context.Background(), c, nil, maxColor,
+		2*runtime.NumCPU())
+	return err
+}
+
+// colorParallelGM2CSR is colorParallelGM2 on the CSR graph layout
+func colorParallelGM2CSR(ctx context.Context, c *graph.CSR, order []int,
+	maxColor, nThreads int) (int, int, error) {
+
+	var wg sync.WaitGroup
+	var m sync.Mutex
+	rounds, conflicts := 0, 0
+
+	u := make([]int, c.NumVertices())
+	for i := range u {
+		u[i] = i
+	}
+	if order != nil {
+		copy(u, order)
+	}
+
+	// uncolor all nodes, as in colorParallelGM
+	for i := range c.Colors {
+		c.Colors[i] = -1
+	}
+
+	// create secondary buffer
+	r := make([]int, 0, len(u)/10)
+
+	// helper function
+	min := func(a, b int) int {
+		if a < b {
+			return a
+		}
+		return b
+	}
+
+	// repeat process until run out of nodes to recolor
+	for len(u) > 0 {
+		if err := ctx.Err(); err != nil {
+			return rounds, conflicts, err
+		}
+		rounds++
+		nVertices := len(u)
+
+		nodesPerThread := nVertices / nThreads
+		if nVertices%nThreads != 0 {
+			nodesPerThread++
+		}
+
+		// speculative coloring
+		wg.Add(nThreads)
+		for i := 0; i < nThreads; i++ {
+			start := min(i*nodesPerThread, nVertices)
+			end := min(start+nodesPerThread, nVertices)
+			go colorNodeParallel2CSR(c, u[start:end], &wg)
+		}
+		wg.Wait()
+
+		// conflict resolution: generate a list of nodes to recolor
+		wg.Add(nThreads)
+		for i := 0; i < nThreads; i++ {
+			start := min(i*nodesPerThread, nVertices)
+			end := min(start+nodesPerThread, nVertices)
+			go checkNodeConflictsParallel2CSR(c, u[start:end], &wg, &r, &m)
+		}
+		wg.Wait()
+
+		// avoid reallocation: reuse buffers
+		tmp := u
+		u = r
+		r = tmp[:0]
+		conflicts += len(u)
+	}
+
+	return rounds, conflicts, color.CheckMaxColor(c, maxColor)
+}
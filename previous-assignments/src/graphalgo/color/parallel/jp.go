@@ -0,0 +1,179 @@
+// This implementation colors independent sets of nodes in rounds, without
+// speculation, following Jones and Plassmann, "A parallel graph coloring
+// heuristic"
+package parallel
+
+import (
+	"context"
+	"graph"
+	"graphalgo/color"
+	"math/rand"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// JPPriority selects the node priorities of ColorJonesPlassmann
+type JPPriority int
+
+const (
+	// JP_RANDOM gives nodes random priorities, so that each round colors an
+	// independent set as in Luby's maximal independent set algorithm
+	JP_RANDOM JPPriority = iota
+
+	// JP_LARGEST_DEGREE_FIRST gives nodes of larger degree higher priority
+	// (breaking ties randomly), which usually uses fewer colors but more
+	// rounds
+	JP_LARGEST_DEGREE_FIRST JPPriority = iota
+)
+
+// ColorJonesPlassmann is the driver for the Jones-Plassmann parallel
+// coloring scheme: in each round, all nodes whose priority is higher than
+// that of all their uncolored neighbors (which form an independent set) are
+// colored in parallel with the smallest color not used by their neighbors.
+// Unlike ColorParallelGM, there are no conflicts to resolve. Each node
+// counts its neighbors of higher priority, and joins the next round when
+// they are all colored, so this takes O(m) work overall. maxColor is an
+// optional bound, as in ColorSequential
+func ColorJonesPlassmann(g graph.Interface, priority JPPriority, maxColor int,
+	seed uint64) error {
+
+	_, err := colorJonesPlassmann(context.Background(), g, priority,
+		maxColor, 2*runtime.NumCPU(), seed)
+	return err
+}
+
+// jpPriorities returns distinct priorities for the nodes of a graph
+func jpPriorities(g graph.Interface, priority JPPriority,
+	seed uint64) []int64 {
+
+	nVertices := g.NumVertices()
+	ranks := rand.New(rand.NewSource(int64(seed))).Perm(nVertices)
+	priorities := make([]int64, nVertices)
+	for i, rank := range ranks {
+		priorities[i] = int64(rank)
+		if priority == JP_LARGEST_DEGREE_FIRST {
+			priorities[i] += int64(g.Degree(i)) * int64(nVertices)
+		}
+	}
+	return priorities
+}
+
+// colorJonesPlassmann is ColorJonesPlassmann with a given number of threads,
+// returning the number of rounds; it stops between rounds if the context is
+// canceled
+func colorJonesPlassmann(ctx context.Context, g graph.Interface,
+	priority JPPriority, maxColor, nThreads int, seed uint64) (int, error) {
+
+	var wg sync.WaitGroup
+	var exceeded int32
+	rounds := 0
+	nVertices := g.NumVertices()
+	priorities := jpPriorities(g, priority, seed)
+
+	// helper function
+	min := func(a, b int) int {
+		if a < b {
+			return a
+		}
+		return b
+	}
+
+	// count the neighbors of higher priority of each node; nodes without
+	// any are colored in the first round
+	nodesPerThread := nVertices / nThreads
+	if nVertices%nThreads != 0 {
+		nodesPerThread++
+	}
+	counts := make([]int32, nVertices)
+	ready := make([][]int, nThreads)
+	wg.Add(nThreads)
+	for t := 0; t < nThreads; t++ {
+		go func(t int) {
+			defer wg.Done()
+			start := min(t*nodesPerThread, nVertices)
+			end := min(start+nodesPerThread, nVertices)
+			for i := start; i < end; i++ {
+				for k := 0; k < g.Degree(i); k++ {
+					if priorities[g.Neighbor(i, k)] > priorities[i] {
+						counts[i]++
+					}
+				}
+				if counts[i] == 0 {
+					ready[t] = append(ready[t], i)
+				}
+			}
+		}(t)
+	}
+	wg.Wait()
+
+	u := make([]int, 0)
+	for t := range ready {
+		u = append(u, ready[t]...)
+	}
+
+	// repeat process until run out of nodes to color
+	for len(u) > 0 {
+		if err := ctx.Err(); err != nil {
+			return rounds, err
+		}
+		rounds++
+		nReady := len(u)
+
+		nodesPerThread := nReady / nThreads
+		if nReady%nThreads != 0 {
+			nodesPerThread++
+		}
+
+		// color the independent set; the neighbors of higher priority are
+		// already colored, and those of lower priority are ignored
+		wg.Add(nThreads)
+		for t := 0; t < nThreads; t++ {
+			go func(t int) {
+				defer wg.Done()
+				var forbidden color.ForbiddenColors
+				ready[t] = ready[t][:0]
+
+				start := min(t*nodesPerThread, nReady)
+				end := min(start+nodesPerThread, nReady)
+				for _, i := range u[start:end] {
+					forbidden.Reset(g.Degree(i))
+					for k := 0; k < g.Degree(i); k++ {
+						j := g.Neighbor(i, k)
+						if priorities[j] > priorities[i] {
+							forbidden.Forbid(g.Color(j))
+						}
+					}
+
+					c := forbidden.First()
+					g.SetColor(i, c)
+					if maxColor > 0 && c >= maxColor {
+						atomic.StoreInt32(&exceeded, 1)
+					}
+
+					// neighbors of lower priority whose neighbors of higher
+					// priority are now all colored join the next round
+					for k := 0; k < g.Degree(i); k++ {
+						j := g.Neighbor(i, k)
+						if priorities[j] < priorities[i] &&
+							atomic.AddInt32(&counts[j], -1) == 0 {
+
+							ready[t] = append(ready[t], j)
+						}
+					}
+				}
+			}(t)
+		}
+		wg.Wait()
+
+		u = u[:0]
+		for t := range ready {
+			u = append(u, ready[t]...)
+		}
+	}
+
+	if exceeded != 0 {
+		return rounds, color.ErrMaxColor
+	}
+	return rounds, nil
+}
@@ -0,0 +1,70 @@
+package color
+
+import (
+	"context"
+	"fmt"
+	"graph"
+	"sort"
+	"sync"
+)
+
+var (
+	registry      = make(map[string]Colorer)
+	registryMutex sync.RWMutex
+)
+
+// Register makes a colorer available by name, e.g., for selecting an
+// algorithm from the command line; it panics if the name is already taken.
+// The subpackages register their colorers in init, so importing them (even
+// as _) is enough to make them available
+func Register(name string, colorer Colorer) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	if _, ok := registry[name]; ok {
+		panic("Colorer " + name + " registered twice")
+	}
+	registry[name] = colorer
+}
+
+// Lookup returns the colorer registered under a name
+func Lookup(name string) (Colorer, error) {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	colorer, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("color: unknown colorer %q (have %v)", name,
+			namesLocked())
+	}
+	return colorer, nil
+}
+
+// Names returns the names of all registered colorers, sorted
+func Names() []string {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	return namesLocked()
+}
+
+// namesLocked is Names, for callers already holding registryMutex
+func namesLocked() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Run colors a graph with the colorer registered under a name
+func Run(ctx context.Context, name string, g graph.Interface,
+	opts Options) (*Result, error) {
+
+	colorer, err := Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	return colorer.Color(ctx, g, opts)
+}
@@ -0,0 +1,79 @@
+package sequential
+
+import (
+	"context"
+	"graph"
+	"graphalgo/color"
+	"time"
+)
+
+func init() {
+	color.Register("sequential", color.ColorerFunc(colorSequential))
+	color.Register("sequential-csr", color.ColorerFunc(colorSequentialCSR))
+	color.Register("dsatur", color.ColorerFunc(colorDSatur))
+}
+
+// colorSequential is ColorSequentialOrdered as a color.Colorer
+func colorSequential(ctx context.Context, g graph.Interface,
+	opts color.Options) (*color.Result, error) {
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	opts, err := opts.WithDefaults(g)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	order := color.Order(g, opts.Ordering, opts.Seed)
+	err = ColorSequentialOrdered(g, order, opts.MaxColor)
+	if err != nil && err != color.ErrMaxColor {
+		return nil, err
+	}
+	return color.NewResult(g, start, 1, 0), err
+}
+
+// colorSequentialCSR is ColorSequentialOrderedCSR as a color.Colorer
+func colorSequentialCSR(ctx context.Context, g graph.Interface,
+	opts color.Options) (*color.Result, error) {
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	opts, err := opts.WithDefaults(g)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	order := color.Order(g, opts.Ordering, opts.Seed)
+	err = color.WithCSR(g, func(c *graph.CSR) error {
+		return ColorSequentialOrderedCSR(c, order, opts.MaxColor)
+	})
+	if err != nil && err != color.ErrMaxColor {
+		return nil, err
+	}
+	return color.NewResult(g, start, 1, 0), err
+}
+
+// colorDSatur is ColorDSatur as a color.Colorer; opts.Ordering is ignored,
+// since DSatur chooses its own order
+func colorDSatur(ctx context.Context, g graph.Interface,
+	opts color.Options) (*color.Result, error) {
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	opts, err := opts.WithDefaults(g)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	err = ColorDSatur(g, opts.MaxColor)
+	if err != nil && err != color.ErrMaxColor {
+		return nil, err
+	}
+	return color.NewResult(g, start, 1, 0), err
+}
@@ -0,0 +1,131 @@
+package sequential
+
+import (
+	"container/heap"
+	"graph"
+	"graphalgo/color"
+)
+
+// ColorDSatur performs the DSatur coloring from Brelaz, "New methods to color
+// the vertices of a graph": it repeatedly colors the uncolored vertex with the
+// most distinctly colored neighbors (its saturation degree), breaking ties by
+// the most uncolored neighbors, with the smallest color not used by its
+// neighbors. It usually needs noticeably fewer colors than ColorSequential,
+// and it's exact on bipartite graphs. Vertices are kept in a heap, so this
+// takes O(m log n) time. maxColor is an optional bound, as in
+// ColorSequential
+func ColorDSatur(g graph.Interface, maxColor int) error {
+	nVertices := g.NumVertices()
+	q := dsaturQueue{
+		vertices:   make([]int, nVertices),
+		position:   make([]int, nVertices),
+		saturation: make([]int, nVertices),
+		degree:     make([]int, nVertices),
+	}
+	for i := 0; i < nVertices; i++ {
+		q.vertices[i] = i
+		q.position[i] = i
+		q.degree[i] = g.Degree(i)
+	}
+	heap.Init(&q)
+
+	// seen has a bit for each vertex and color up to its degree, set if the
+	// vertex has a neighbor of that color (i.e., if the color is counted in
+	// its saturation); larger colors are rare, and kept in seenLarge
+	offsets := make([]int, nVertices+1)
+	for i := 0; i < nVertices; i++ {
+		offsets[i+1] = offsets[i] + g.Degree(i)/64 + 1
+	}
+	seen := make([]uint64, offsets[nVertices])
+	seenLarge := make(map[[2]int]bool)
+	markSeen := func(i, c int) bool {
+		if c > g.Degree(i) {
+			if seenLarge[[2]int{i, c}] {
+				return false
+			}
+			seenLarge[[2]int{i, c}] = true
+			return true
+		}
+		word, bit := &seen[offsets[i]+c/64], uint64(1)<<uint(c%64)
+		if *word&bit != 0 {
+			return false
+		}
+		*word |= bit
+		return true
+	}
+
+	var forbidden color.ForbiddenColors
+	colored := make([]bool, nVertices)
+	exceeded := false
+
+	for q.Len() > 0 {
+		i := heap.Pop(&q).(int)
+
+		forbidden.Reset(g.Degree(i))
+		for k := 0; k < g.Degree(i); k++ {
+			if j := g.Neighbor(i, k); colored[j] {
+				forbidden.Forbid(g.Color(j))
+			}
+		}
+
+		c := forbidden.First()
+		g.SetColor(i, c)
+		colored[i] = true
+		if maxColor > 0 && c >= maxColor {
+			exceeded = true
+		}
+
+		// update the priorities of the uncolored neighbors
+		for k := 0; k < g.Degree(i); k++ {
+			j := g.Neighbor(i, k)
+			if colored[j] {
+				continue
+			}
+			q.degree[j]--
+			if markSeen(j, c) {
+				q.saturation[j]++
+			}
+			heap.Fix(&q, q.position[j])
+		}
+	}
+
+	if exceeded {
+		return color.ErrMaxColor
+	}
+	return nil
+}
+
+// dsaturQueue is a max-heap of the uncolored vertices by saturation degree,
+// then by uncolored degree, then by lowest index
+type dsaturQueue struct {
+	vertices   []int // heap of vertices
+	position   []int // index of each vertex in the heap
+	saturation []int // number of distinct neighbor colors of each vertex
+	degree     []int // number of uncolored neighbors of each vertex
+}
+
+func (q *dsaturQueue) Len() int { return len(q.vertices) }
+func (q *dsaturQueue) Less(a, b int) bool {
+	i, j := q.vertices[a], q.vertices[b]
+	if q.saturation[i] != q.saturation[j] {
+		return q.saturation[i] > q.saturation[j]
+	}
+	if q.degree[i] != q.degree[j] {
+		return q.degree[i] > q.degree[j]
+	}
+	return i < j
+}
+func (q *dsaturQueue) Swap(a, b int) {
+	q.vertices[a], q.vertices[b] = q.vertices[b], q.vertices[a]
+	q.position[q.vertices[a]] = a
+	q.position[q.vertices[b]] = b
+}
+func (q *dsaturQueue) Push(x interface{}) {
+	q.position[x.(int)] = len(q.vertices)
+	q.vertices = append(q.vertices, x.(int))
+}
+func (q *dsaturQueue) Pop() interface{} {
+	i := q.vertices[len(q.vertices)-1]
+	q.vertices = q.vertices[:len(q.vertices)-1]
+	return i
+}
@@ -1,33 +1,96 @@
 package sequential
 
-import "graph"
+import (
+	"graph"
+	"graphalgo/color"
+)
 
 // ColorSequential performs a naive sequential.go Delta+1 coloring
-// (suboptimal chromatic number, but very simple valid coloring)
-func ColorSequential(g *graph.Graph, maxColor int) {
-	neighborColors := make([]bool, maxColor)
-	neighborColorsDefault := make([]bool, maxColor)
+// (suboptimal chromatic number, but very simple valid coloring), in O(m)
+// time (see color.ForbiddenColors). maxColor is an optional bound (0 for
+// none): if more colors are needed, the coloring is still finished but
+// color.ErrMaxColor is returned
+func ColorSequential(g graph.Interface, maxColor int) error {
+	return ColorSequentialOrdered(g, nil, maxColor)
+}
+
+// ColorSequentialOrdered is ColorSequential, coloring the vertices in a given
+// order (see color.Order), or in index order if order is nil. Each vertex
+// gets the smallest color not used by its neighbors colored before it, so the
+// initial colors don't matter
+func ColorSequentialOrdered(g graph.Interface, order []int,
+	maxColor int) error {
+
+	var forbidden color.ForbiddenColors
+	colored := make([]bool, g.NumVertices())
+	exceeded := false
+
+	for k := 0; k < g.NumVertices(); k++ {
+		i := k
+		if order != nil {
+			i = order[k]
+		}
+
+		forbidden.Reset(g.Degree(i))
+		for l := 0; l < g.Degree(i); l++ {
+			if j := g.Neighbor(i, l); colored[j] {
+				forbidden.Forbid(g.Color(j))
+			}
+		}
+
+		j := forbidden.First()
+		g.SetColor(i, j)
+		colored[i] = true
+		if maxColor > 0 && j >= maxColor {
+			exceeded = true
+		}
+	}
 
-	for i := range g.Vertices {
-		v := &g.Vertices[i]
+	if exceeded {
+		return color.ErrMaxColor
+	}
+	return nil
+}
 
-		copy(neighborColors, neighborColorsDefault)
+// ColorSequentialCSR performs the same naive Delta+1 coloring as
+// ColorSequential, but specialized to the CSR graph layout to avoid the
+// overhead of graph.Interface
+func ColorSequentialCSR(c *graph.CSR, maxColor int) error {
+	return ColorSequentialOrderedCSR(c, nil, maxColor)
+}
 
-		for _, j := range v.Adj {
-			neighborColors[g.Vertices[j].Value] = true
+// ColorSequentialOrderedCSR is ColorSequentialOrdered on the CSR graph layout
+func ColorSequentialOrderedCSR(c *graph.CSR, order []int,
+	maxColor int) error {
+
+	var forbidden color.ForbiddenColors
+	colored := make([]bool, c.NumVertices())
+	exceeded := false
+
+	for k := range c.Colors {
+		i := k
+		if order != nil {
+			i = order[k]
 		}
 
-		colorFound := false
-		for j := 0; j < maxColor; j++ {
-			if !neighborColors[j] {
-				g.Vertices[i].Value = j
-				colorFound = true
-				break
+		neighbors := c.Neighbors(i)
+		forbidden.Reset(len(neighbors))
+		for _, j := range neighbors {
+			if colored[j] {
+				forbidden.Forbid(int(c.Colors[j]))
 			}
 		}
 
-		if !colorFound {
-			panic("maxColor exceeded")
+		j := forbidden.First()
+		c.Colors[i] = int32(j)
+		colored[i] = true
+		if maxColor > 0 && j >= maxColor {
+			exceeded = true
 		}
 	}
+
+	if exceeded {
+		return color.ErrMaxColor
+	}
+	return nil
 }
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"graph"
+	"log"
+	"os"
+)
+
+// main converts a graph file between the text and binary formats, or exports
+// it to DOT or GraphML for inspection; the input format is detected
+// automatically by graph.Load, including gzip- and zstd-compressed input
+func main() {
+	inFile := flag.String("in", "", "Input graph file")
+	outFile := flag.String("out", "", "Output graph file")
+	format := flag.String("format", "text",
+		"Output format: text, binary, dot or graphml")
+	binary := flag.Bool("binary", false,
+		"Write the binary format (same as -format binary)")
+	colors := flag.Bool("colors", true,
+		"Include vertex colors in the binary format")
+	gzip := flag.Bool("gzip", false, "Compress the output with gzip")
+	zstd := flag.Bool("zstd", false, "Compress the output with zstd")
+	flag.Parse()
+
+	if *inFile == "" || *outFile == "" {
+		log.Fatal("Both -in and -out must be specified.")
+	}
+	if *gzip && *zstd {
+		log.Fatal("Only one of -gzip and -zstd can be specified.")
+	}
+	if *binary {
+		if *format != "text" && *format != "binary" {
+			log.Fatal("-binary can't be combined with -format " + *format)
+		}
+		*format = "binary"
+	}
+
+	// read input graph
+	log.Printf("Reading graph file %s...\n", *inFile)
+	file, err := os.Open(*inFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	g, err := graph.Load(file)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = file.Close()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// write output graph
+	log.Printf("Writing graph file %s...\n", *outFile)
+	file, err = os.OpenFile(*outFile, os.O_CREATE|os.O_TRUNC|os.O_WRONLY,
+		0666)
+	if err != nil {
+		log.Fatal(err)
+	}
+	bufWriter := bufio.NewWriter(file)
+	compression := graph.COMPRESSION_NONE
+	if *gzip {
+		compression = graph.COMPRESSION_GZIP
+	} else if *zstd {
+		compression = graph.COMPRESSION_ZSTD
+	}
+	writer, err := graph.Compress(bufWriter, compression)
+	if err != nil {
+		log.Fatal(err)
+	}
+	switch *format {
+	case "text":
+		err = g.Dump(writer)
+	case "binary":
+		err = g.DumpBinary(writer, *colors)
+	case "dot":
+		err = g.DumpDOT(writer)
+	case "graphml":
+		err = g.DumpGraphML(writer)
+	default:
+		log.Fatalf("Unknown output format %s.\n", *format)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = writer.Close()
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = bufWriter.Flush()
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = file.Close()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("Done\n")
+}
@@ -32,6 +32,8 @@ const (
 
 	// MSG_SUBGRAPH is for sending subgraph
 	MSG_SUBGRAPH = byte(iota)
+	// MSG_VERTEX_RANGES server gives workers the vertex range of each worker
+	MSG_VERTEX_RANGES = byte(iota)
 
 	// MSG_CONT indicates not to send a message type, this buffer is a
 	// continuation of the last byte buffer
@@ -50,6 +52,7 @@ var NUM_BYTES_MAP = map[byte]int{
 	MSG_NODE_ADDRESS:        7,  // 0: node index, 1-4: ipv4 address, 5-6 port
 	MSG_DIALER_INDEX:        1,  // 0: incoming node index
 	MSG_SUBGRAPH:            -1, // variable length string until DELIM_EOF
+	MSG_VERTEX_RANGES:       -1, // comma-separated range starts, and end
 	MSG_NODE_ROUND_START:    1,  // 0: node index
 }
 
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"graph"
+	"graph/stats"
+	"log"
+	"os"
+)
+
+// main prints the statistics of a graph file (see graph/stats), as text or
+// as JSON; the input format is detected automatically by graph.Load,
+// including gzip- and zstd-compressed input
+func main() {
+	inFile := flag.String("in", "", "Input graph file")
+	asJSON := flag.Bool("json", false, "Print statistics as JSON")
+	flag.Parse()
+
+	if *inFile == "" {
+		log.Fatal("-in must be specified.")
+	}
+
+	file, err := os.Open(*inFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	g, err := graph.Load(file)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = file.Close()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	s := stats.Compute(g)
+	if *asJSON {
+		err = s.WriteJSON(os.Stdout)
+		if err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		fmt.Print(s)
+	}
+}
@@ -1,28 +1,111 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"flag"
 	"fmt"
 	"graph"
-	"graphalgo/color/sequential"
+	"graphalgo/color"
+	_ "graphalgo/color/distributed"
+	_ "graphalgo/color/parallel"
+	_ "graphalgo/color/sequential"
+	"log"
+	"os"
+	"runtime"
+	"strings"
 )
 
 // main is a sample entrypoint to show how to generate a graph and use the
 // graph coloring functions, but you can see that most of our tests and
-// benchmarks are in proj1_test.go
+// benchmarks are in proj1_test.go. With -out, it instead streams a generated
+// graph to file (see generateStream)
 func main() {
+	outFile := flag.String("out", "",
+		"Stream a generated graph to this file instead")
+	nVertices := flag.Int("n", 1000000, "Number of vertices (random graph)")
+	degree := flag.Float64("degree", 10, "Average degree (random graph)")
+	scale := flag.Int("rmat", 0,
+		"Generate a Graph500 R-MAT graph with 2^rmat vertices instead")
+	seed := flag.Uint64("seed", 0, "Random seed")
+	binary := flag.Bool("binary", false, "Write the binary format")
+	runSize := flag.Int("runsize", graph.DefaultRunSize,
+		"Max adjacency entries sorted in memory at once")
+	algorithm := flag.String("algorithm", "sequential",
+		"Coloring algorithm, one of "+strings.Join(color.Names(), ", "))
+	orderingName := flag.String("ordering", color.ORDER_NATURAL.String(),
+		"Vertex ordering of greedy coloring algorithms")
+	flag.Parse()
+
+	if *outFile != "" {
+		generateStream(*outFile, *nVertices, float32(*degree), *scale, *seed,
+			graph.StreamOptions{
+				Binary:   *binary,
+				NThreads: 2 * runtime.NumCPU(),
+				RunSize:  *runSize,
+			})
+		return
+	}
+
+	ordering, err := color.ParseOrdering(*orderingName)
+	if err != nil {
+		log.Panic(err)
+	}
+
 	N := 12000
 
 	fmt.Printf("Generating complete graph...\n")
 	completeGraph := graph.NewCompleteGraph(N)
 
-	// maxColor for a very simple coloring color
-	maxColor := 3 * N / 2
-
-	// perform coloring
-	fmt.Printf("Graph coloring...\n")
-	//color.ColorParallelGM(&completeGraph, maxColor)
-	sequential.ColorSequential(&completeGraph, maxColor)
+	// perform coloring (without a bound on the number of colors, which
+	// greedy coloring keeps within Delta+1 anyway)
+	fmt.Printf("Graph coloring with %s (%v order)...\n", *algorithm,
+		ordering)
+	result, err := color.Run(context.Background(), *algorithm,
+		&completeGraph, color.Options{Ordering: ordering, Seed: *seed})
+	if err != nil {
+		log.Panic(err)
+	}
+	fmt.Printf("%d colors, %d rounds, %d conflicts in %v\n",
+		result.NumColors, result.Rounds, result.Conflicts, result.Elapsed)
 
 	// check that the graph coloring worked
 	fmt.Printf("isColored: %t", completeGraph.CheckValidColoring())
 }
+
+// generateStream writes a random (or R-MAT) graph to file without building
+// it in memory, so that it can be larger than RAM; the output (text or
+// binary) can be passed straight to the proj2 server
+func generateStream(outFile string, nVertices int, degree float32,
+	scale int, seed uint64, opts graph.StreamOptions) {
+
+	log.Printf("Creating graph file %s...\n", outFile)
+	file, err := os.OpenFile(outFile, os.O_CREATE|os.O_TRUNC|os.O_WRONLY,
+		0666)
+	if err != nil {
+		log.Panic(err)
+	}
+	writer := bufio.NewWriter(file)
+
+	log.Printf("Generating and writing graph...\n")
+	if scale > 0 {
+		err = graph.WriteRMATGraph(writer, graph.Graph500RMATParams(scale,
+			seed), opts)
+	} else {
+		err = graph.WriteRandomGraph(writer, nVertices, degree, seed, opts)
+	}
+	if err != nil {
+		log.Panic(err)
+	}
+
+	err = writer.Flush()
+	if err != nil {
+		log.Panic(err)
+	}
+	err = file.Close()
+	if err != nil {
+		log.Panic(err)
+	}
+
+	log.Printf("Done\n")
+}
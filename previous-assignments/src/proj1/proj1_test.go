@@ -1,11 +1,27 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
 	"graph"
+	"graph/stats"
+	"graphalgo/color"
+	"graphalgo/color/distributed"
 	"graphalgo/color/parallel"
 	"graphalgo/color/sequential"
+	"io"
+	"io/ioutil"
+	"log"
 	"math"
 	"math/rand"
+	"os"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync/atomic"
 	"testing"
 )
 
@@ -28,9 +44,9 @@ func TestAverageDegree(t *testing.T) {
 	for i := 0; i < 20; i++ {
 		N := maxGraphSize/2 + rand.Int()%(maxGraphSize/2)
 		desiredBf := rand.Float64() * float64(N) * maxBfRatio
-		actualBf := float64(countEdges(
-			graph.NewRandomGraphParallel(N, float32(desiredBf), 50))) /
-			float64(N)
+		g := graph.NewRandomGraphParallel(N, float32(desiredBf), uint64(i),
+			50)
+		actualBf := float64(countEdges(g)) / float64(N)
 
 		t.Logf("Test: NewRandomGraph(%d, %f)", N, desiredBf)
 
@@ -41,6 +57,756 @@ func TestAverageDegree(t *testing.T) {
 	}
 }
 
+// TestSparse checks that a large sparse random graph has the expected average
+// degree; BenchmarkNewRandomGraphParallelSparse times a million vertices
+func TestSparse(t *testing.T) {
+	const N, deg, tolerance = 50000, 10, 0.05
+
+	g := graph.NewRandomGraphParallel(N, deg, 1, 8)
+	actualBf := float64(countEdges(g)) / float64(N)
+	t.Logf("Test: NewRandomGraphParallel(%d, %d): average degree %f", N,
+		deg, actualBf)
+
+	if math.Abs(deg-actualBf) > tolerance*deg {
+		t.Errorf("Branching factor error. Got %f, desired %d", actualBf, deg)
+	}
+}
+
+// TestDenseDegree checks that the random generators give complete (or
+// complete k-partite) graphs when the degree is at least the number of
+// possible neighbors
+func TestDenseDegree(t *testing.T) {
+	const N = 10
+
+	for _, deg := range []float32{N - 1, 20} {
+		graphs := map[string]graph.Graph{
+			"NewRandomGraph": graph.NewRandomGraph(N, deg, 1),
+			"NewRandomGraphParallel": graph.NewRandomGraphParallel(N, deg,
+				1, 4),
+		}
+
+		var buf bytes.Buffer
+		err := graph.WriteRandomGraph(&buf, N, deg, 1, graph.StreamOptions{})
+		if err != nil {
+			t.Fatalf("WriteRandomGraph: %v", err)
+		}
+		g, err := graph.Load(&buf)
+		if err != nil {
+			t.Fatalf("WriteRandomGraph: %v", err)
+		}
+		graphs["WriteRandomGraph"] = *g
+
+		for name, g := range graphs {
+			if countEdges(g) != N*(N-1) {
+				t.Errorf("%s(%d, %f) isn't complete: %d edges", name, N, deg,
+					countEdges(g)/2)
+			}
+		}
+	}
+
+	const k = 2
+	g, coloring := graph.NewPlantedGraph(100, k, 60, 1)
+	for i := range g.Vertices {
+		// every vertex is adjacent to the whole other part
+		nOther := 0
+		for j := range coloring {
+			if coloring[j] != coloring[i] {
+				nOther++
+			}
+		}
+		if len(g.Vertices[i].Adj) != nOther {
+			t.Errorf("NewPlantedGraph(100, %d, 60) isn't complete "+
+				"%d-partite", k, k)
+			break
+		}
+	}
+}
+
+// TestPowerLaw checks that the power-law generators have the expected
+// average degree and produce hubs
+func TestPowerLaw(t *testing.T) {
+	const N, m, tolerance = 5000, 5, 0.20
+
+	generators := map[string]func() graph.Graph{
+		"NewBarabasiAlbertGraph": func() graph.Graph {
+			return graph.NewBarabasiAlbertGraph(N, m, 1)
+		},
+		"NewBarabasiAlbertGraphParallel": func() graph.Graph {
+			return graph.NewBarabasiAlbertGraphParallel(N, m, 1, 8)
+		},
+	}
+
+	for name, generate := range generators {
+		g := generate()
+		avgDegree := float64(countEdges(g)) / float64(N)
+		t.Logf("Test: %s(%d, %d): average degree %f, max degree %d",
+			name, N, m, avgDegree, stats.MaxDegree(&g))
+
+		if math.Abs(avgDegree-2*m) > tolerance*2*m {
+			t.Errorf("%s: average degree %f, expected %d", name,
+				avgDegree, 2*m)
+		}
+		if stats.MaxDegree(&g) < 10*m {
+			t.Errorf("%s: no hubs, max degree %d", name, stats.MaxDegree(&g))
+		}
+		if _, err := g.Validate(false); err != nil {
+			t.Errorf("%s: invalid graph: %v", name, err)
+		}
+	}
+
+	degrees := graph.PowerLawDegrees(N, 2.5, 2, 500, 1)
+	g := graph.NewConfigurationGraph(degrees, 1)
+	sum := 0
+	for i, d := range degrees {
+		sum += d
+		if len(g.Vertices[i].Adj) > d {
+			t.Errorf("Vertex %d has degree %d > target %d", i,
+				len(g.Vertices[i].Adj), d)
+		}
+	}
+	t.Logf("Test: NewConfigurationGraph: %d of %d stubs used",
+		countEdges(g), sum)
+	if float64(countEdges(g)) < (1-tolerance)*float64(sum) {
+		t.Errorf("Configuration model dropped too many edges")
+	}
+	if _, err := g.Validate(false); err != nil {
+		t.Errorf("NewConfigurationGraph: invalid graph: %v", err)
+	}
+}
+
+// TestRMAT checks that R-MAT generation only depends on the seed, and that
+// the streamed edge list matches the in-memory graph
+func TestRMAT(t *testing.T) {
+	params := graph.Graph500RMATParams(12, 42)
+
+	g1 := graph.NewRMATGraph(params, 1)
+	g7 := graph.NewRMATGraph(params, 7)
+	var buf1, buf7 bytes.Buffer
+	g1.Dump(&buf1)
+	g7.Dump(&buf7)
+	if !bytes.Equal(buf1.Bytes(), buf7.Bytes()) {
+		t.Errorf("R-MAT graph depends on the number of threads")
+	}
+	t.Logf("Test: NewRMATGraph(scale 12): %d edges, max degree %d",
+		countEdges(g1)/2, stats.MaxDegree(&g1))
+
+	// streamed edge list should be identical for any number of threads
+	buf1.Reset()
+	buf7.Reset()
+	if err := graph.WriteRMATEdgeList(&buf1, params, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.WriteRMATEdgeList(&buf7, params, 3); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf1.Bytes(), buf7.Bytes()) {
+		t.Errorf("R-MAT edge list depends on the number of threads")
+	}
+
+	h, _, err := graph.LoadEdgeList(&buf1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if countEdges(*h) != countEdges(g1) {
+		t.Errorf("R-MAT edge list has %d edges, graph has %d",
+			countEdges(*h)/2, countEdges(g1)/2)
+	}
+
+	params.Seed++
+	g := graph.NewRMATGraph(params, 7)
+	buf1.Reset()
+	buf7.Reset()
+	g1.Dump(&buf1)
+	g.Dump(&buf7)
+	if bytes.Equal(buf1.Bytes(), buf7.Bytes()) {
+		t.Errorf("R-MAT graph doesn't depend on the seed")
+	}
+}
+
+// dumpString is a helper that returns a graph in the text format, so that
+// graphs can be compared
+func dumpString(g graph.Graph) string {
+	var buf bytes.Buffer
+	g.Dump(&buf)
+	return buf.String()
+}
+
+// TestSeeded checks that seeded generators always generate the same graph
+// for the same seed, independently of the number of threads, and different
+// graphs for different seeds
+func TestSeeded(t *testing.T) {
+	const N, deg, m = 2000, 20, 4
+
+	generators := map[string]func(seed uint64, nThreads int) graph.Graph{
+		"NewRandomGraphParallel": func(seed uint64, nThreads int) graph.Graph {
+			return graph.NewRandomGraphParallel(N, deg, seed, nThreads)
+		},
+		"NewBarabasiAlbertGraphParallel": func(seed uint64,
+			nThreads int) graph.Graph {
+
+			return graph.NewBarabasiAlbertGraphParallel(N, m, seed, nThreads)
+		},
+		"NewBarabasiAlbertGraph": func(seed uint64, _ int) graph.Graph {
+			return graph.NewBarabasiAlbertGraph(N, m, seed)
+		},
+		"NewConfigurationGraph": func(seed uint64, _ int) graph.Graph {
+			degrees := graph.PowerLawDegrees(N, 2.5, 2, 100, seed)
+			return graph.NewConfigurationGraph(degrees, seed)
+		},
+		"NewPlantedGraph": func(seed uint64, _ int) graph.Graph {
+			g, _ := graph.NewPlantedGraph(N, 5, deg, seed)
+			return g
+		},
+		"NewRandomRegularGraph": func(seed uint64, _ int) graph.Graph {
+			g, _ := graph.NewRandomRegularGraph(N, 6, seed)
+			return g
+		},
+	}
+
+	for name, generate := range generators {
+		dump := dumpString(generate(42, 1))
+		for _, nThreads := range []int{1, 3, 8} {
+			if dumpString(generate(42, nThreads)) != dump {
+				t.Errorf("%s: graph depends on the number of threads "+
+					"(%d)", name, nThreads)
+			}
+		}
+		if dumpString(generate(43, 1)) == dump {
+			t.Errorf("%s: graph doesn't depend on the seed", name)
+		}
+	}
+
+	// the sequential random graph generator draws the same edges
+	if dumpString(graph.NewRandomGraph(N, deg, 42)) !=
+		dumpString(graph.NewRandomGraphParallel(N, deg, 42, 8)) {
+
+		t.Errorf("NewRandomGraph and NewRandomGraphParallel differ")
+	}
+}
+
+// TestStream checks that the streaming generators write the same files as
+// dumping the in-memory generators, even when split into many small runs
+func TestStream(t *testing.T) {
+	const N, deg, seed = 5000, 20, 42
+
+	g := graph.NewRandomGraph(N, deg, seed)
+	var textBuf, binBuf bytes.Buffer
+	g.Dump(&textBuf)
+	g.DumpBinary(&binBuf, false)
+
+	rmatParams := graph.Graph500RMATParams(10, seed)
+	h := graph.NewRMATGraph(rmatParams, 4)
+	var rmatBuf bytes.Buffer
+	h.Dump(&rmatBuf)
+
+	for _, runSize := range []int{1000, graph.DefaultRunSize} {
+		for _, isBinary := range []bool{false, true} {
+			opts := graph.StreamOptions{
+				Binary:   isBinary,
+				NThreads: 3,
+				RunSize:  runSize,
+			}
+			expected := textBuf.Bytes()
+			if isBinary {
+				expected = binBuf.Bytes()
+			}
+
+			var buf bytes.Buffer
+			err := graph.WriteRandomGraph(&buf, N, deg, seed, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf.Bytes(), expected) {
+				t.Errorf("WriteRandomGraph (binary %t, run size %d) "+
+					"differs from NewRandomGraph", isBinary, runSize)
+			}
+		}
+
+		var buf bytes.Buffer
+		err := graph.WriteRMATGraph(&buf, rmatParams,
+			graph.StreamOptions{NThreads: 2, RunSize: runSize})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf.Bytes(), rmatBuf.Bytes()) {
+			t.Errorf("WriteRMATGraph (run size %d) differs from "+
+				"NewRMATGraph", runSize)
+		}
+	}
+}
+
+// TestStreamShard checks that streamed graphs, in both formats, can be
+// sharded like the proj2 server does, into subgraphs of the same vertices
+// in the text format, and that corrupted binary files are rejected
+func TestStreamShard(t *testing.T) {
+	const N, deg, seed, nWorkers = 2000, 10, 7, 3
+
+	g := graph.NewRandomGraph(N, deg, seed)
+	weights := make([]int, N)
+	for i := range weights {
+		weights[i] = 1
+	}
+	ranges, err := graph.WeightedRanges(weights, nWorkers)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, isBinary := range []bool{false, true} {
+		var buf bytes.Buffer
+		err := graph.WriteRandomGraph(&buf, N, deg, seed,
+			graph.StreamOptions{Binary: isBinary, NThreads: 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		vertexReader, err := graph.NewVertexReader(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if vertexReader.NumVertices != N {
+			t.Fatalf("Streamed graph (binary %t) has %d vertices, "+
+				"expected %d", isBinary, vertexReader.NumVertices, N)
+		}
+		for k := 0; k < nWorkers; k++ {
+			var subgraphBuf bytes.Buffer
+			err := vertexReader.WriteSubgraph(&subgraphBuf,
+				ranges[k+1]-ranges[k])
+			if err != nil {
+				t.Fatal(err)
+			}
+			h, err := graph.Load(&subgraphBuf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(h.Vertices) != ranges[k+1]-ranges[k] {
+				t.Fatalf("Subgraph %d (binary %t) has %d vertices, "+
+					"expected %d", k, isBinary, len(h.Vertices),
+					ranges[k+1]-ranges[k])
+			}
+			for i := range h.Vertices {
+				if !reflect.DeepEqual(h.Vertices[i].Adj,
+					g.Vertices[ranges[k]+i].Adj) {
+
+					t.Fatalf("Subgraph %d (binary %t) has wrong neighbors "+
+						"of vertex %d", k, isBinary, ranges[k]+i)
+				}
+			}
+		}
+		if _, err := vertexReader.Next(); err != io.EOF {
+			t.Errorf("Streamed graph (binary %t) didn't end after the "+
+				"last subgraph: %v", isBinary, err)
+		}
+	}
+
+	// flip a neighbor index in the last adjacency entry of a binary file,
+	// which is only caught by the checksum
+	var buf bytes.Buffer
+	err = graph.WriteRandomGraph(&buf, N, deg, seed,
+		graph.StreamOptions{Binary: true, NThreads: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	corrupted := buf.Bytes()
+	corrupted[len(corrupted)-8] ^= 1
+	vertexReader, err := graph.NewVertexReader(bytes.NewReader(corrupted))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = vertexReader.WriteSubgraph(ioutil.Discard, N)
+	if err == nil {
+		_, err = vertexReader.Next()
+	}
+	if err != graph.ErrBadChecksum {
+		t.Errorf("Corrupted binary graph gave %v, expected %v", err,
+			graph.ErrBadChecksum)
+	}
+}
+
+// isKColorable is a helper that checks by backtracking whether a (small)
+// graph can be colored with k colors
+func isKColorable(g graph.Graph, k int) bool {
+	colors := make([]int, len(g.Vertices))
+	for i := range colors {
+		colors[i] = -1
+	}
+
+	var colorFrom func(i int) bool
+	colorFrom = func(i int) bool {
+		if i == len(colors) {
+			return true
+		}
+		for c := 0; c < k; c++ {
+			ok := true
+			for _, j := range g.Vertices[i].Adj {
+				if colors[j] == c {
+					ok = false
+					break
+				}
+			}
+			if ok {
+				colors[i] = c
+				if colorFrom(i + 1) {
+					return true
+				}
+			}
+		}
+		colors[i] = -1
+		return false
+	}
+
+	return colorFrom(0)
+}
+
+// TestStructured checks the structured generators: small instances must
+// have exactly the chromatic number they claim, and greedy coloring must
+// find an optimal coloring where index order allows it
+func TestStructured(t *testing.T) {
+	type generated struct {
+		g         graph.Graph
+		chi       int
+		nEdges    int
+		isSmall   bool
+		isRegular bool
+	}
+	wrap := func(g graph.Graph, chi int) generated {
+		return generated{g: g, chi: chi}
+	}
+
+	tests := map[string]generated{}
+	add := func(name string, gen generated, nEdges int, isSmall bool) {
+		gen.nEdges, gen.isSmall = nEdges, isSmall
+		tests[name] = gen
+	}
+	add("NewGridGraph(4, 5)", wrap(graph.NewGridGraph(4, 5)), 31, true)
+	add("NewGridGraph(1, 1)", wrap(graph.NewGridGraph(1, 1)), 0, true)
+	add("NewGrid3DGraph(3, 3, 2)", wrap(graph.NewGrid3DGraph(3, 3, 2)),
+		33, true)
+	add("NewTorusGraph(4, 6)", wrap(graph.NewTorusGraph(4, 6)), 48, true)
+	add("NewTorusGraph(3, 4)", wrap(graph.NewTorusGraph(3, 4)), 24, true)
+	add("NewTorusGraph(2, 5)", wrap(graph.NewTorusGraph(2, 5)), 15, true)
+	add("NewTorus3DGraph(3, 2, 2)", wrap(graph.NewTorus3DGraph(3, 2, 2)),
+		24, true)
+	add("NewTorus3DGraph(10, 10, 10)",
+		wrap(graph.NewTorus3DGraph(10, 10, 10)), 3000, false)
+	add("NewHypercubeGraph(4)", wrap(graph.NewHypercubeGraph(4)), 32, true)
+	add("NewHypercubeGraph(10)", wrap(graph.NewHypercubeGraph(10)),
+		5120, false)
+	add("NewCompleteBipartiteGraph(3, 7)",
+		wrap(graph.NewCompleteBipartiteGraph(3, 7)), 21, true)
+	add("NewCompleteBipartiteGraph(0, 4)",
+		wrap(graph.NewCompleteBipartiteGraph(0, 4)), 0, true)
+	add("NewMycielskiGraph(3)", wrap(graph.NewMycielskiGraph(3)), 5, true)
+	add("NewMycielskiGraph(4)", wrap(graph.NewMycielskiGraph(4)), 20, true)
+	add("NewMycielskiGraph(5)", wrap(graph.NewMycielskiGraph(5)), 71, true)
+	add("NewMycielskiGraph(7)", wrap(graph.NewMycielskiGraph(7)), 755,
+		false)
+
+	gen := wrap(graph.NewRandomRegularBipartiteGraph(500, 6, 1))
+	gen.isRegular = true
+	add("NewRandomRegularBipartiteGraph(500, 6)", gen, 3000, false)
+
+	// the chromatic number of random regular graphs is exact for k <= 2,
+	// and for the complete graph K_4
+	for _, nk := range [][2]int{{10, 1}, {15, 2}, {16, 2}, {4, 3}} {
+		gen := wrap(graph.NewRandomRegularGraph(nk[0], nk[1], 1))
+		gen.isRegular = true
+		add(fmt.Sprintf("NewRandomRegularGraph(%d, %d)", nk[0], nk[1]), gen,
+			nk[0]*nk[1]/2, true)
+	}
+
+	// greedy coloring in index order is exact on these graphs, except on the
+	// odd tori, where the wraparound costs an extra color, and on cycles in
+	// random order
+	greedyColors := map[string]int{
+		"NewTorusGraph(3, 4)":          4,
+		"NewTorusGraph(2, 5)":          4,
+		"NewTorus3DGraph(3, 2, 2)":     4,
+		"NewRandomRegularGraph(15, 2)": 3,
+		"NewRandomRegularGraph(16, 2)": 3,
+	}
+
+	for name, test := range tests {
+		g := test.g
+		if _, err := g.Validate(false); err != nil {
+			t.Errorf("%s: invalid graph: %v", name, err)
+			continue
+		}
+		if countEdges(g) != 2*test.nEdges {
+			t.Errorf("%s: %d edges, expected %d", name, countEdges(g)/2,
+				test.nEdges)
+		}
+		nAdj := len(g.Vertices) * stats.MaxDegree(&g)
+		if test.isRegular && countEdges(g) != nAdj {
+			t.Errorf("%s: graph isn't regular", name)
+		}
+
+		sequential.ColorSequential(&g, 0)
+		nColors := countColors(&g)
+		t.Logf("Test: %s: chromatic number %d, ColorSequential used %d",
+			name, test.chi, nColors)
+		if !g.CheckValidColoring() {
+			t.Errorf("%s is improperly colored", name)
+		}
+		maxColors, ok := greedyColors[name]
+		if !ok {
+			maxColors = test.chi
+		}
+		if nColors > maxColors {
+			t.Errorf("%s: colored with %d > %d colors", name, nColors,
+				maxColors)
+		}
+
+		if test.isSmall && (!isKColorable(g, test.chi) ||
+			(test.chi > 0 && isKColorable(g, test.chi-1))) {
+
+			t.Errorf("%s: chromatic number isn't %d", name, test.chi)
+		}
+	}
+
+	// myciel3 in the DIMACS benchmarks is M_4
+	file, err := os.Open("../../res/myciel3.col")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	h, err := graph.LoadDIMACS(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, _ := graph.NewMycielskiGraph(4)
+	if len(h.Vertices) != len(g.Vertices) || countEdges(*h) != countEdges(g) {
+		t.Errorf("NewMycielskiGraph(4) doesn't match myciel3.col")
+	}
+
+	// larger random regular graphs only have Brooks' bound, which is k
+	// unless they have a complete component
+	N, k := 200, 7
+	g, chi := graph.NewRandomRegularGraph(N, k, 1)
+	if _, err := g.Validate(false); err != nil {
+		t.Errorf("NewRandomRegularGraph: invalid graph: %v", err)
+	}
+	if countEdges(g) != N*k || stats.MaxDegree(&g) != k {
+		t.Errorf("NewRandomRegularGraph(%d, %d) isn't %d-regular", N, k, k)
+	}
+	if chi != k {
+		t.Errorf("NewRandomRegularGraph(%d, %d): chromatic bound %d, "+
+			"expected %d", N, k, chi, k)
+	}
+}
+
+// TestPlanted reports how many more colors than the planted k each colorer
+// uses on planted k-colorable graphs of increasing density
+func TestPlanted(t *testing.T) {
+	const N, k = 2000, 8
+
+	for _, deg := range []float32{2, 5, 10, 20, 50, 100} {
+		g, coloring := graph.NewPlantedGraph(N, k, deg, 1)
+
+		// the planted coloring must be valid and use at most k colors
+		for i := range g.Vertices {
+			if coloring[i] < 0 || coloring[i] >= k {
+				t.Fatalf("Planted color %d out of range", coloring[i])
+			}
+			for _, j := range g.Vertices[i].Adj {
+				if coloring[i] == coloring[j] {
+					t.Fatalf("Planted coloring conflict on edge (%d, %d)",
+						i, j)
+				}
+			}
+		}
+
+		for _, name := range color.Names() {
+			for i := range g.Vertices {
+				g.Vertices[i].Value = 0
+			}
+			result, err := color.Run(context.Background(), name, &g,
+				color.Options{NThreads: 8})
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if !g.CheckValidColoring() {
+				t.Errorf("%s: planted graph (degree %f) is improperly "+
+					"colored", name, deg)
+			}
+
+			t.Logf("Test: NewPlantedGraph(%d, %d, %f): %s used %d colors "+
+				"(%+d)", N, k, deg, name, result.NumColors,
+				result.NumColors-k)
+		}
+	}
+}
+
+// TestStats checks the graph statistics against graphs with known structure
+func TestStats(t *testing.T) {
+	const N = 30
+
+	g := graph.NewCompleteGraph(N)
+	s := stats.Compute(&g)
+	if s.NumEdges != N*(N-1)/2 || s.MaxDegree != N-1 || s.MinDegree != N-1 ||
+		s.Degeneracy != N-1 || s.NumTriangles != N*(N-1)*(N-2)/6 ||
+		s.Clustering != 1 || s.Transitivity != 1 || s.IsBipartite ||
+		s.NumComponents != 1 {
+
+		t.Errorf("Wrong statistics for NewCompleteGraph(%d): %+v", N, s)
+	}
+
+	g, _ = graph.NewGridGraph(N, N)
+	s = stats.Compute(&g)
+	if s.NumEdges != 2*N*(N-1) || s.MaxDegree != 4 || s.MinDegree != 2 ||
+		s.DegreeHistogram[3] != 4*(N-2) || s.Degeneracy != 2 ||
+		s.NumTriangles != 0 || !s.IsBipartite {
+
+		t.Errorf("Wrong statistics for NewGridGraph(%d, %d): %+v", N, N, s)
+	}
+
+	// Mycielski graphs are triangle-free, but not bipartite
+	g, _ = graph.NewMycielskiGraph(6)
+	s = stats.Compute(&g)
+	if s.NumTriangles != 0 || s.IsBipartite {
+		t.Errorf("Wrong statistics for NewMycielskiGraph(6): %+v", s)
+	}
+
+	// two disjoint rings
+	g = graph.NewRingGraph(N)
+	for i := 0; i < N; i++ {
+		g.AddNode(0)
+	}
+	for i := N; i < 2*N; i++ {
+		g.AddUndirectedEdge(i, N+(i+1-N)%N)
+	}
+	components, nComponents := stats.Components(&g)
+	if nComponents != 2 || components[0] != 0 || components[2*N-1] != 1 {
+		t.Errorf("Expected 2 components, got %d", nComponents)
+	}
+	s = stats.Compute(&g)
+	if s.LargestComponent != N || s.Degeneracy != 2 || !s.IsBipartite {
+		t.Errorf("Wrong statistics for two rings: %+v", s)
+	}
+
+	// count triangles and core numbers by brute force
+	g = graph.NewRandomGraph(200, 20, 1)
+	s = stats.Compute(graph.NewCSR(&g))
+	isAdjacent := make(map[[2]int]bool)
+	for i := range g.Vertices {
+		for _, j := range g.Vertices[i].Adj {
+			isAdjacent[[2]int{i, j}] = true
+		}
+	}
+	nTriangles := 0
+	for i := range g.Vertices {
+		for j := i + 1; j < len(g.Vertices); j++ {
+			if !isAdjacent[[2]int{i, j}] {
+				continue
+			}
+			for k := j + 1; k < len(g.Vertices); k++ {
+				if isAdjacent[[2]int{i, k}] && isAdjacent[[2]int{j, k}] {
+					nTriangles++
+				}
+			}
+		}
+	}
+	if s.NumTriangles != nTriangles {
+		t.Errorf("Counted %d triangles, expected %d", s.NumTriangles,
+			nTriangles)
+	}
+
+	// a vertex's core number is the min degree of the subgraph induced by
+	// vertices of at least that core number
+	cores := stats.CoreNumbers(&g)
+	for i, core := range cores {
+		inCore := 0
+		for _, j := range g.Vertices[i].Adj {
+			if cores[j] >= core {
+				inCore++
+			}
+		}
+		if inCore < core {
+			t.Errorf("Vertex %d has core number %d, but only %d neighbors "+
+				"in the core", i, core, inCore)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := s.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"num_triangles": `) {
+		t.Errorf("Missing statistics in JSON: %s", buf.String())
+	}
+}
+
+// TestMutation checks edge and vertex removal, canonicalization and induced
+// subgraphs, including that attributes stay parallel to the vertices
+func TestMutation(t *testing.T) {
+	g := graph.NewCompleteGraph(5)
+	g.NewAttributes(true, true, true)
+	for i := range g.Vertices {
+		g.Attrs.VertexWeights[i] = 10 * i
+		g.Attrs.VertexLabels[i] = string(rune('a' + i))
+		for k, j := range g.Vertices[i].Adj {
+			g.Attrs.EdgeWeights[i][k] = 10*i + j
+		}
+	}
+
+	if !g.HasEdge(1, 3) || !g.RemoveEdge(1, 3) || g.HasEdge(3, 1) ||
+		g.RemoveEdge(3, 1) {
+
+		t.Errorf("RemoveEdge(1, 3) failed")
+	}
+
+	remap := g.RemoveVertex(2)
+	if len(g.Vertices) != 4 || remap[2] != -1 || remap[4] != 3 {
+		t.Fatalf("RemoveVertex(2): %d vertices, remap %v", len(g.Vertices),
+			remap)
+	}
+	if g.HasEdge(1, 2) || !g.HasEdge(0, 2) || !g.HasEdge(2, 3) ||
+		g.Attrs.VertexWeights[3] != 40 || g.Attrs.VertexLabels[2] != "d" {
+
+		t.Errorf("RemoveVertex(2) didn't compact the graph")
+	}
+	if _, err := g.Validate(false); err != nil {
+		t.Errorf("RemoveVertex(2): invalid graph: %v", err)
+	}
+	for i := range g.Vertices {
+		for k, j := range g.Vertices[i].Adj {
+			// vertex weights are still 10 times the old indices
+			oldI, oldJ := g.VertexWeight(i)/10, g.VertexWeight(j)/10
+			if g.EdgeWeight(i, k) != 10*oldI+oldJ {
+				t.Errorf("Edge weight of (%d, %d) is %d", i, j,
+					g.EdgeWeight(i, k))
+			}
+		}
+	}
+
+	// duplicates are dropped, keeping the first weight
+	g.AddWeightedUndirectedEdge(3, 0, 1)
+	g.AddWeightedUndirectedEdge(0, 3, 2)
+	g.Canonicalize()
+	if len(g.Vertices[0].Adj) != 3 || g.Vertices[0].Adj[2] != 3 ||
+		g.EdgeWeight(0, 2) != 4 {
+
+		t.Errorf("Canonicalize: adjacency %v, weights %v",
+			g.Vertices[0].Adj, g.Attrs.EdgeWeights[0])
+	}
+
+	h := graph.NewRandomGraph(100, 10, 1)
+	vertices := []int{50, 3, 99, 10, 42, 7}
+	sub, ids := h.InducedSubgraph(vertices)
+	for k := range sub.Vertices {
+		if ids[k] != int64(vertices[k]) {
+			t.Errorf("InducedSubgraph: vertex %d has id %d", k, ids[k])
+		}
+		for l := range sub.Vertices {
+			if sub.HasEdge(k, l) != h.HasEdge(vertices[k], vertices[l]) {
+				t.Errorf("InducedSubgraph: wrong edge (%d, %d)", k, l)
+			}
+		}
+	}
+	if _, err := sub.Validate(false); err != nil {
+		t.Errorf("InducedSubgraph: invalid graph: %v", err)
+	}
+}
+
 // TestSequential checks that the sequential coloring works
 func TestSequential(t *testing.T) {
 	N := 1000
@@ -62,7 +828,7 @@ func TestSequential(t *testing.T) {
 	}
 
 	t.Logf("Test: NewRandomGraph(%d, %f)", N, deg)
-	g = graph.NewRandomGraph(N, deg)
+	g = graph.NewRandomGraph(N, deg, 1)
 	sequential.ColorSequential(&g, maxColor)
 	if !g.CheckValidColoring() {
 		t.Errorf("NewRandomGraph is improperly colored")
@@ -90,7 +856,7 @@ func TestParallel(t *testing.T) {
 	}
 
 	t.Logf("Test: NewRandomGraph(%d, %f)", N, deg)
-	g = graph.NewRandomGraph(N, deg)
+	g = graph.NewRandomGraph(N, deg, 1)
 	parallel.ColorParallelGM(&g, maxColor)
 	if !g.CheckValidColoring() {
 		t.Errorf("NewRandomGraph is improperly colored")
@@ -111,13 +877,955 @@ func TestParallel(t *testing.T) {
 	}
 
 	t.Logf("Test: NewRandomGraph(%d, %f)", N, deg)
-	g = graph.NewRandomGraph(N, deg)
+	g = graph.NewRandomGraph(N, deg, 1)
 	parallel.ColorParallelGM2(&g, maxColor)
 	if !g.CheckValidColoring() {
 		t.Errorf("NewRandomGraph is improperly colored")
 	}
 }
 
+// TestCSR checks that the colorers work on the CSR layout and that colors
+// are copied back to the original graph
+func TestCSR(t *testing.T) {
+	N := 1000
+	deg := float32(30)
+	maxColor := 1000
+
+	colorers := map[string]func(*graph.CSR, int) error{
+		"ColorSequentialCSR":  sequential.ColorSequentialCSR,
+		"ColorParallelGMCSR":  parallel.ColorParallelGMCSR,
+		"ColorParallelGM2CSR": parallel.ColorParallelGM2CSR,
+	}
+
+	for name, colorer := range colorers {
+		t.Logf("Test: %s(NewRandomGraph(%d, %f))", name, N, deg)
+		g := graph.NewRandomGraph(N, deg, 1)
+		c := graph.NewCSR(&g)
+		if c.NumVertices() != N || len(c.Adj) != countEdges(g) {
+			t.Errorf("%s: CSR has wrong size", name)
+		}
+
+		colorer(c, maxColor)
+		if !c.CheckValidColoring() {
+			t.Errorf("%s: CSR is improperly colored", name)
+		}
+
+		c.CopyColors(&g)
+		if !g.CheckValidColoring() {
+			t.Errorf("%s: copied coloring is invalid", name)
+		}
+	}
+}
+
+// TestInterface checks that the generic colorers work on the CSR layout
+// through graph.Interface, rather than through color.WithCSR like the -csr
+// colorers
+func TestInterface(t *testing.T) {
+	N := 1000
+	deg := float32(30)
+
+	for _, name := range []string{"sequential", "gm", "gm2", "dsatur", "jp",
+		"jp-ldf", "distributed"} {
+
+		t.Logf("Test: %s(NewCSR(NewRandomGraph(%d, %f)))", name, N, deg)
+		g := graph.NewRandomGraph(N, deg, 1)
+		c := graph.NewCSR(&g)
+		_, err := color.Run(context.Background(), name, c, color.Options{})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !graph.IsValidColoring(c) || !c.CheckValidColoring() {
+			t.Errorf("%s: CSR is improperly colored", name)
+		}
+	}
+}
+
+// TestRegistry checks that every registered colorer properly colors both
+// graph layouts within Delta+1 colors and describes its run, and that
+// canceled and unknown colorings fail
+func TestRegistry(t *testing.T) {
+	const N, deg = 1000, 30
+
+	names := color.Names()
+	for _, name := range []string{"sequential", "sequential-csr", "gm",
+		"gm2", "gm-csr", "gm2-csr", "distributed"} {
+
+		if _, err := color.Lookup(name); err != nil {
+			t.Errorf("Colorer %s isn't registered (have %v)", name, names)
+		}
+	}
+
+	for _, name := range names {
+		g := graph.NewRandomGraph(N, deg, 1)
+		c := graph.NewCSR(&g)
+		maxDegree := stats.MaxDegree(&g)
+
+		for _, gi := range []graph.Interface{&g, c} {
+			t.Logf("Test: %s(%T)", name, gi)
+			result, err := color.Run(context.Background(), name, gi,
+				color.Options{})
+			if err != nil {
+				t.Errorf("%s: %v", name, err)
+				continue
+			}
+			if !graph.IsValidColoring(gi) {
+				t.Errorf("%s: %T is improperly colored", name, gi)
+			}
+			if result.NumColors != color.NumColors(gi) ||
+				result.NumColors > maxDegree+1 {
+
+				t.Errorf("%s: used %d colors (Delta = %d)", name,
+					result.NumColors, maxDegree)
+			}
+			if result.Rounds < 1 || result.Conflicts < 0 ||
+				result.Elapsed <= 0 {
+
+				t.Errorf("%s: invalid result %+v", name, *result)
+			}
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := color.Run(ctx, name, &g, color.Options{}); err == nil {
+			t.Errorf("%s: canceled coloring didn't fail", name)
+		}
+	}
+
+	g := graph.NewRandomGraph(N, deg, 1)
+	_, err := color.Run(context.Background(), "nonexistent", &g,
+		color.Options{})
+	if err == nil {
+		t.Errorf("Unknown colorer didn't fail")
+	}
+
+	// only the distributed colorer can run as a worker of a cluster
+	ws := distributed.NewWorkerState()
+	ws.Subgraph = &g
+	for _, name := range []string{"gm2", "nonexistent"} {
+		_, err := distributed.RunWorker(context.Background(), name, ws,
+			color.Options{}, log.New(ioutil.Discard, "", 0))
+		if err == nil {
+			t.Errorf("%s: worker coloring didn't fail", name)
+		}
+	}
+}
+
+// TestOrdering checks that every ordering is a permutation of the vertices
+// with its defining property, and that the greedy colorers color properly in
+// every order
+func TestOrdering(t *testing.T) {
+	const N, m = 2000, 5
+	g := graph.NewBarabasiAlbertGraph(N, m, 1)
+	degeneracy := stats.Degeneracy(&g)
+
+	for _, ordering := range color.Orderings() {
+		if o, err := color.ParseOrdering(ordering.String()); o != ordering ||
+			err != nil {
+
+			t.Errorf("ParseOrdering(%q) = %v, %v", ordering.String(), o, err)
+		}
+
+		order := color.Order(&g, ordering, 1)
+		seen := make([]bool, N)
+		for _, i := range order {
+			if i < 0 || i >= N || seen[i] {
+				t.Fatalf("%v: order isn't a permutation", ordering)
+			}
+			seen[i] = true
+		}
+		if len(order) != N {
+			t.Fatalf("%v: order has %d vertices", ordering, len(order))
+		}
+
+		switch ordering {
+		case color.ORDER_LARGEST_FIRST:
+			for k := 1; k < N; k++ {
+				if g.Degree(order[k]) > g.Degree(order[k-1]) {
+					t.Errorf("%v: degrees aren't decreasing", ordering)
+					break
+				}
+			}
+		case color.ORDER_INCIDENCE_DEGREE:
+			// the graph is connected, so every vertex but the first has a
+			// neighbor before it
+			position := make([]int, N)
+			for k, i := range order {
+				position[i] = k
+			}
+			for _, i := range order[1:] {
+				hasPrevious := false
+				for _, j := range g.Vertices[i].Adj {
+					hasPrevious = hasPrevious || position[j] < position[i]
+				}
+				if !hasPrevious {
+					t.Errorf("%v: vertex %d has no neighbor before it",
+						ordering, i)
+					break
+				}
+			}
+		}
+
+		for _, name := range []string{"sequential", "sequential-csr", "gm",
+			"gm2", "gm-csr", "gm2-csr"} {
+
+			for i := range g.Vertices {
+				g.Vertices[i].Value = 0
+			}
+			result, err := color.Run(context.Background(), name, &g,
+				color.Options{Ordering: ordering, Seed: 1})
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if !g.CheckValidColoring() {
+				t.Errorf("%s: graph is improperly colored in %v order",
+					name, ordering)
+			}
+			if strings.HasPrefix(name, "sequential") &&
+				ordering == color.ORDER_SMALLEST_LAST &&
+				result.NumColors > degeneracy+1 {
+
+				t.Errorf("%s: smallest-last used %d colors (degeneracy "+
+					"%d)", name, result.NumColors, degeneracy)
+			}
+			t.Logf("Test: %s(NewBarabasiAlbertGraph(%d, %d)) in %v order "+
+				"used %d colors", name, N, m, ordering, result.NumColors)
+		}
+	}
+
+	if _, err := color.ParseOrdering("nonexistent"); err == nil {
+		t.Errorf("Unknown ordering didn't fail")
+	}
+
+	// colorers reject unknown orderings, even if they ignore the ordering
+	for _, name := range color.Names() {
+		for _, ordering := range []color.Ordering{-1, 9} {
+			_, err := color.Run(context.Background(), name, &g,
+				color.Options{Ordering: ordering})
+			if err == nil {
+				t.Errorf("%s: unknown ordering %v didn't fail", name,
+					ordering)
+			}
+		}
+	}
+}
+
+// TestDSatur checks that DSatur colors bipartite graphs with two colors, and
+// reports how many colors it saves over ColorSequential on random graphs
+func TestDSatur(t *testing.T) {
+	type bipartiteGraph struct {
+		name string
+		g    graph.Graph
+		chi  int
+	}
+	var bipartite []bipartiteGraph
+	add := func(name string, g graph.Graph, chi int) {
+		bipartite = append(bipartite, bipartiteGraph{name, g, chi})
+	}
+
+	// the structured generators also return the chromatic number
+	g, chi := graph.NewGridGraph(30, 40)
+	add("NewGridGraph(30, 40)", g, chi)
+	g, chi = graph.NewTorusGraph(20, 30)
+	add("NewTorusGraph(20, 30)", g, chi)
+	g, chi = graph.NewHypercubeGraph(10)
+	add("NewHypercubeGraph(10)", g, chi)
+	add("NewRingGraph(1000)", graph.NewRingGraph(1000), 2)
+	g, chi = graph.NewCompleteBipartiteGraph(30, 70)
+	add("NewCompleteBipartiteGraph(30, 70)", g, chi)
+	g, chi = graph.NewRandomRegularBipartiteGraph(500, 6, 1)
+	add("NewRandomRegularBipartiteGraph(500, 6)", g, chi)
+
+	for _, b := range bipartite {
+		if err := sequential.ColorDSatur(&b.g, 0); err != nil {
+			t.Errorf("%s: %v", b.name, err)
+		}
+		if !b.g.CheckValidColoring() || countColors(&b.g) != b.chi {
+			t.Errorf("%s: DSatur used %d colors, expected %d", b.name,
+				countColors(&b.g), b.chi)
+		}
+	}
+
+	N := 1000
+	g = graph.NewCompleteGraph(N)
+	if err := sequential.ColorDSatur(&g, 0); err != nil {
+		t.Fatal(err)
+	}
+	if !g.CheckValidColoring() || countColors(&g) != N {
+		t.Errorf("NewCompleteGraph: DSatur used %d colors", countColors(&g))
+	}
+
+	for _, deg := range []float32{5, 20, 100} {
+		g := graph.NewRandomGraph(N, deg, 1)
+		if err := sequential.ColorSequential(&g, 0); err != nil {
+			t.Fatal(err)
+		}
+		nSequential := countColors(&g)
+		if err := sequential.ColorDSatur(&g, 0); err != nil {
+			t.Fatal(err)
+		}
+		if !g.CheckValidColoring() {
+			t.Errorf("NewRandomGraph(%d, %f) is improperly colored", N, deg)
+		}
+		t.Logf("Test: NewRandomGraph(%d, %f): DSatur used %d colors, "+
+			"ColorSequential %d", N, deg, countColors(&g), nSequential)
+	}
+}
+
+// TestJonesPlassmann checks that Jones-Plassmann colors without conflicts,
+// and that its coloring only depends on the seed, not on the number of
+// threads
+func TestJonesPlassmann(t *testing.T) {
+	const N = 2000
+
+	for _, deg := range []float32{5, 50, 500} {
+		for _, name := range []string{"jp", "jp-ldf"} {
+			g := graph.NewRandomGraph(N, deg, 1)
+			result, err := color.Run(context.Background(), name, &g,
+				color.Options{NThreads: 1, Seed: 1})
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if !g.CheckValidColoring() || result.Conflicts != 0 {
+				t.Errorf("%s: NewRandomGraph(%d, %f) is improperly colored",
+					name, N, deg)
+			}
+			t.Logf("Test: %s(NewRandomGraph(%d, %f)) used %d colors in %d "+
+				"rounds", name, N, deg, result.NumColors, result.Rounds)
+
+			expected := dumpString(g)
+			_, err = color.Run(context.Background(), name, &g,
+				color.Options{NThreads: 8, Seed: 1})
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if dumpString(g) != expected {
+				t.Errorf("%s: coloring depends on the number of threads",
+					name)
+			}
+		}
+	}
+}
+
+// TestMaxColor checks that every registered colorer works without a color
+// bound, even if neighbors start with arbitrarily large colors, and that a
+// bound below the chromatic number fails with ErrMaxColor but still leaves a
+// valid coloring, and describes it
+func TestMaxColor(t *testing.T) {
+	const N, deg = 1000, 30
+
+	for _, name := range color.Names() {
+		t.Logf("Test: %s(NewRandomGraph(%d, %d)) with large colors", name,
+			N, deg)
+		g := graph.NewRandomGraph(N, deg, 1)
+		for i := range g.Vertices {
+			g.Vertices[i].Value = 10000 + i
+		}
+		_, err := color.Run(context.Background(), name, &g, color.Options{})
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if !g.CheckValidColoring() {
+			t.Errorf("%s: graph is improperly colored", name)
+		}
+
+		t.Logf("Test: %s(NewCompleteGraph(%d)) with %d colors", name, 100,
+			50)
+		g = graph.NewCompleteGraph(100)
+		result, err := color.Run(context.Background(), name, &g,
+			color.Options{MaxColor: 50})
+		if err != color.ErrMaxColor {
+			t.Errorf("%s: got error %v, expected %v", name, err,
+				color.ErrMaxColor)
+		}
+		if result == nil || result.NumColors != 100 {
+			t.Errorf("%s: got result %+v, expected 100 colors", name,
+				result)
+		}
+		if !g.CheckValidColoring() {
+			t.Errorf("%s: graph is improperly colored", name)
+		}
+	}
+}
+
+// staleGraph is a helper for TestMaxColorRecolored, which shows colorers
+// stale colors, as speculative threads may see each other's: in the first
+// round, vertex 0 sees vertices 2 and 3 colored 0 and 1 (and no other
+// colors), and until it's recolored, it sees vertex 1 with its own color 2
+type staleGraph struct {
+	*graph.Graph
+	nColored   int32 // colors set, not counting uncoloring
+	nRecolored int32 // colors set of vertex 0
+}
+
+func (g *staleGraph) Color(i int) int {
+	if atomic.LoadInt32(&g.nColored) < int32(len(g.Vertices)) {
+		switch i {
+		case 2:
+			return 0
+		case 3:
+			return 1
+		}
+		return -1
+	}
+	if i == 1 && atomic.LoadInt32(&g.nRecolored) < 2 {
+		return 2
+	}
+	return g.Graph.Color(i)
+}
+
+func (g *staleGraph) SetColor(i, c int) {
+	if c >= 0 {
+		atomic.AddInt32(&g.nColored, 1)
+		if i == 0 {
+			atomic.AddInt32(&g.nRecolored, 1)
+		}
+	}
+	g.Graph.SetColor(i, c)
+}
+
+// TestMaxColorRecolored checks that the speculative colorers decide the
+// color bound from the final colors: vertex 0 of a star first goes above the
+// bound, but it conflicts and is recolored below it
+func TestMaxColorRecolored(t *testing.T) {
+	for _, name := range []string{"gm", "gm2"} {
+		g := graph.New(4)
+		for j := 1; j < 4; j++ {
+			g.AddUndirectedEdge(0, j)
+		}
+		result, err := color.Run(context.Background(), name,
+			&staleGraph{Graph: &g}, color.Options{MaxColor: 2})
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if result.Conflicts != 1 || !g.CheckValidColoring() ||
+			g.Vertices[0].Value != 1 {
+
+			t.Errorf("%s: got result %+v and colors %v", name, result,
+				[]int{g.Vertices[0].Value, g.Vertices[1].Value,
+					g.Vertices[2].Value, g.Vertices[3].Value})
+		}
+	}
+}
+
+// TestBinaryRoundTrip checks that graphs survive a round trip through the
+// binary format, that Load detects both formats, and that bad binary files
+// and graphs the format can't hold are rejected
+func TestBinaryRoundTrip(t *testing.T) {
+	N := 1000
+	deg := float32(30)
+	maxColor := 1000
+
+	g := graph.NewRandomGraph(N, deg, 1)
+	sequential.ColorSequential(&g, maxColor)
+
+	var binBuf, textBuf bytes.Buffer
+	if err := g.DumpBinary(&binBuf, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Dump(&textBuf); err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("Binary size %d, text size %d", binBuf.Len(), textBuf.Len())
+
+	for name, buf := range map[string][]byte{
+		"binary": binBuf.Bytes(),
+		"text":   textBuf.Bytes(),
+	} {
+		h, err := graph.Load(bytes.NewReader(buf))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(h.Vertices) != N || countEdges(*h) != countEdges(g) {
+			t.Errorf("%s: loaded graph has wrong size", name)
+		}
+		if !h.CheckValidColoring() {
+			t.Errorf("%s: loaded graph is improperly colored", name)
+		}
+	}
+
+	// corrupting a byte should be caught by the checksum
+	corrupt := append([]byte{}, binBuf.Bytes()...)
+	corrupt[len(corrupt)/2] ^= 0xff
+	if _, err := graph.Load(bytes.NewReader(corrupt)); err == nil {
+		t.Errorf("Corrupted binary graph was loaded without error")
+	}
+
+	// huge counts in a truncated file should fail without allocating for
+	// them (the checksum can only be checked at the end)
+	huge := append([]byte{}, binBuf.Bytes()[:16]...)
+	binary.LittleEndian.PutUint32(huge[8:], 1<<30)
+	binary.LittleEndian.PutUint32(huge[12:], 1<<31-1)
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	if _, err := graph.Load(bytes.NewReader(huge)); err == nil {
+		t.Errorf("Truncated binary graph was loaded without error")
+	}
+	runtime.ReadMemStats(&after)
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 1<<24 {
+		t.Errorf("Truncated binary graph allocated %d bytes", allocated)
+	}
+
+	// the binary format has no room for attributes
+	g.NewAttributes(true, false, false)
+	if err := g.DumpBinary(&binBuf, true); err != graph.ErrBinaryAttributes {
+		t.Errorf("Got error %v for graph with attributes, expected %v", err,
+			graph.ErrBinaryAttributes)
+	}
+}
+
+// countColors is a helper that returns the number of distinct colors used
+func countColors(g *graph.Graph) int {
+	colors := make(map[int]bool)
+	for i := range g.Vertices {
+		colors[g.Vertices[i].Value] = true
+	}
+	return len(colors)
+}
+
+// TestDIMACS checks that DIMACS benchmark graphs are loaded and colored
+// within known color counts: DSatur finds their chromatic number, and
+// ColorSequential stays within a recorded bound
+func TestDIMACS(t *testing.T) {
+	benchmarks := []struct {
+		file       string
+		nVertices  int
+		nEdges     int
+		chromaticN int
+		maxColors  int // colors used by ColorSequential
+	}{
+		{"myciel3.col", 11, 20, 4, 4},
+		{"myciel4.col", 23, 71, 5, 5},
+		{"queen5_5.col", 25, 160, 5, 8},
+	}
+
+	for _, bm := range benchmarks {
+		t.Logf("Test: LoadDIMACS(%s)", bm.file)
+		file, err := os.Open("../../res/" + bm.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g, err := graph.LoadDIMACS(file)
+		file.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", bm.file, err)
+		}
+		if len(g.Vertices) != bm.nVertices || countEdges(*g) != 2*bm.nEdges {
+			t.Errorf("%s: loaded graph has wrong size", bm.file)
+		}
+
+		if err := sequential.ColorSequential(g, 0); err != nil {
+			t.Fatalf("%s: %v", bm.file, err)
+		}
+		if !g.CheckValidColoring() {
+			t.Errorf("%s is improperly colored", bm.file)
+		}
+		if nColors := countColors(g); nColors > bm.maxColors {
+			t.Errorf("%s: ColorSequential used %d > %d colors", bm.file,
+				nColors, bm.maxColors)
+		}
+
+		if err := sequential.ColorDSatur(g, 0); err != nil {
+			t.Fatalf("%s: %v", bm.file, err)
+		}
+		if !g.CheckValidColoring() {
+			t.Errorf("%s is improperly colored by ColorDSatur", bm.file)
+		}
+		if nColors := countColors(g); nColors != bm.chromaticN {
+			t.Errorf("%s: ColorDSatur used %d colors, chromatic number "+
+				"is %d", bm.file, nColors, bm.chromaticN)
+		}
+
+		// round trip through DumpDIMACS
+		var buf bytes.Buffer
+		if err := g.DumpDIMACS(&buf); err != nil {
+			t.Fatal(err)
+		}
+		h, err := graph.LoadDIMACS(&buf)
+		if err != nil {
+			t.Fatalf("%s: %v", bm.file, err)
+		}
+		if countEdges(*h) != countEdges(*g) {
+			t.Errorf("%s: DIMACS round trip changed edge count", bm.file)
+		}
+	}
+
+	// an edge listed by only one endpoint is written once, and counted
+	g := graph.New(3)
+	g.Vertices[2].Adj = []int{0}
+	var buf bytes.Buffer
+	if err := g.DumpDIMACS(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "p edge 3 1\ne 1 3\n" {
+		t.Errorf("Edge stored in one direction was dumped as %q",
+			buf.String())
+	}
+
+	// invalid files should be rejected
+	for _, bad := range []string{
+		"e 1 2\n",
+		"p col 3 1\ne 1 2\n",
+		"p edge 3 1\ne 1 4\n",
+		"p edge 3 1\ne 0 1\n",
+		"p edge 3 1\ne 2 2\n",
+	} {
+		if _, err := graph.LoadDIMACS(strings.NewReader(bad)); err == nil {
+			t.Errorf("Invalid DIMACS file %q was loaded without error", bad)
+		}
+	}
+}
+
+// TestMETIS checks that METIS graphs and their weights survive a round trip
+func TestMETIS(t *testing.T) {
+	// example graph from the METIS manual, with vertex and edge weights
+	const metisGraph = `% comment line
+7 11 011
+4 5 1 3 2 2 1
+2 1 1 3 2 4 1
+5 5 3 4 2 2 2 1 2
+3 2 1 3 2 6 2 7 5
+1 1 1 3 3 6 2
+6 5 2 4 2 7 6
+2 6 6 4 5
+`
+
+	g, weights, err := graph.LoadMETIS(strings.NewReader(metisGraph))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Vertices) != 7 || countEdges(*g) != 22 {
+		t.Errorf("METIS graph has wrong size")
+	}
+	if weights == nil || weights.Vertex[2][0] != 5 || weights.Edge[3][2] != 2 {
+		t.Errorf("METIS weights were not kept")
+	}
+
+	var buf bytes.Buffer
+	if err := g.DumpMETIS(&buf, weights); err != nil {
+		t.Fatal(err)
+	}
+	h, hWeights, err := graph.LoadMETIS(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if countEdges(*h) != countEdges(*g) ||
+		hWeights.Vertex[6][0] != weights.Vertex[6][0] ||
+		hWeights.Edge[6][1] != weights.Edge[6][1] {
+
+		t.Errorf("METIS round trip changed the graph")
+	}
+
+	// an edge count mismatch should be rejected
+	_, _, err = graph.LoadMETIS(strings.NewReader("2 2\n2\n1\n"))
+	if err == nil {
+		t.Errorf("Invalid METIS file was loaded without error")
+	}
+}
+
+// TestEdgeList checks that sparse edge lists and Matrix Market files are
+// compacted, symmetrized and mapped back to their original ids
+func TestEdgeList(t *testing.T) {
+	const edgeList = `# SNAP-style comment
+1000000007 42
+42 1000000007
+42 42
+5 42 1.5
+`
+	g, ids, err := graph.LoadEdgeList(strings.NewReader(edgeList))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Vertices) != 3 || countEdges(*g) != 4 {
+		t.Errorf("Edge list graph has wrong size")
+	}
+	if len(ids) != 3 || ids[0] != 1000000007 || ids[1] != 42 || ids[2] != 5 {
+		t.Errorf("Edge list ids were not mapped correctly: %v", ids)
+	}
+
+	g.Vertices[1].Value = 1
+	var buf bytes.Buffer
+	if err := g.DumpColoring(&buf, ids); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "1000000007 0\n42 1\n5 0\n" {
+		t.Errorf("Unexpected coloring output %q", buf.String())
+	}
+
+	const matrixMarket = `%%MatrixMarket matrix coordinate pattern symmetric
+% comment
+4 4 3
+2 1
+3 3
+4 2
+`
+	g, ids, err = graph.LoadMatrixMarket(strings.NewReader(matrixMarket))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Vertices) != 4 || countEdges(*g) != 4 || ids[3] != 4 {
+		t.Errorf("Matrix Market graph has wrong size")
+	}
+}
+
+// TestLoadStrict checks that LoadStrict reports each kind of problem with
+// its line number, and repairs the fixable ones
+func TestLoadStrict(t *testing.T) {
+	cases := []struct {
+		file string
+		kind graph.ValidationErrorKind
+		line int
+	}{
+		{"2\n0;1\n0\n", graph.ERR_MALFORMED_LINE, 3},
+		{"1\n0;\n0;\n", graph.ERR_COUNT_MISMATCH, 1},
+		{"2\n0;1\n0;0,2\n", graph.ERR_OUT_OF_RANGE, 3},
+		{"2\n0;0,1\n0;0\n", graph.ERR_SELF_LOOP, 2},
+		{"2\n0;1\n0;0,0\n", graph.ERR_DUPLICATE_EDGE, 3},
+		{"3\n0;1\n0;0,2\n0;\n", graph.ERR_MISSING_REVERSE_EDGE, 3},
+	}
+
+	for _, c := range cases {
+		_, _, err := graph.LoadStrict(strings.NewReader(c.file), false)
+		verr, ok := err.(*graph.ValidationError)
+		if !ok || verr.Kind != c.kind || verr.Line != c.line {
+			t.Errorf("LoadStrict(%q): expected kind %d on line %d, got %v",
+				c.file, c.kind, c.line, err)
+		}
+
+		// fixable problems should be repaired
+		g, repaired, err := graph.LoadStrict(strings.NewReader(c.file), true)
+		if c.kind == graph.ERR_MALFORMED_LINE ||
+			c.kind == graph.ERR_OUT_OF_RANGE {
+
+			if err == nil {
+				t.Errorf("LoadStrict(%q): unfixable problem repaired", c.file)
+			}
+			continue
+		}
+		if err != nil || len(repaired) != 1 || repaired[0].Kind != c.kind {
+			t.Errorf("LoadStrict(%q): repair failed: %v", c.file, err)
+			continue
+		}
+		if _, err := g.Validate(false); err != nil {
+			t.Errorf("LoadStrict(%q): repaired graph is invalid: %v",
+				c.file, err)
+		}
+	}
+
+	// Load shouldn't panic on malformed input either
+	if _, err := graph.Load(strings.NewReader("1\n0;\n0;\n")); err == nil {
+		t.Errorf("Load accepted too many vertices")
+	}
+}
+
+// TestAttributes checks that vertex/edge weights and labels survive a round
+// trip through the text format, and are kept in sync by repairs
+func TestAttributes(t *testing.T) {
+	g := graph.New(3)
+	g.NewAttributes(true, false, true)
+	g.AddWeightedUndirectedEdge(0, 1, 5)
+	g.AddUndirectedEdge(1, 2)
+	g.Attrs.VertexWeights[2] = 7
+	g.Attrs.VertexLabels[0] = "a;b"
+	g.Vertices[1].Value = 1
+
+	var buf bytes.Buffer
+	if err := g.Dump(&buf); err != nil {
+		t.Fatal(err)
+	}
+	h, err := graph.Load(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.VertexWeight(2) != 7 || h.VertexWeight(0) != 1 ||
+		h.EdgeWeight(1, 0) != 5 || h.EdgeWeight(1, 1) != 1 ||
+		h.VertexLabel(0) != "a;b" || h.Vertices[1].Value != 1 {
+
+		t.Errorf("Attributes changed in round trip")
+	}
+
+	// repairing a missing reverse edge should copy its weight
+	const file = "2 e\n0;1;9\n0;;\n"
+	h, _, err = graph.LoadStrict(strings.NewReader(file), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.EdgeWeight(0, 0) != 9 || h.EdgeWeight(1, 0) != 9 {
+		t.Errorf("Repaired edge has wrong weight")
+	}
+
+	// METIS weights are kept as attributes
+	h, _, err = graph.LoadMETIS(strings.NewReader("2 1 011\n4 2 3\n5 1 3\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.VertexWeight(1) != 5 || h.EdgeWeight(0, 0) != 3 {
+		t.Errorf("METIS weights weren't kept as attributes")
+	}
+}
+
+// TestWeightedRanges checks that vertex ranges are split by cumulative
+// weight, and by vertex count if there are no weights
+func TestWeightedRanges(t *testing.T) {
+	cases := []struct {
+		weights []int
+		nParts  int
+		ranges  []int
+	}{
+		{[]int{1, 1, 1, 1, 1, 1, 1, 1, 1, 1}, 3, []int{0, 4, 7, 10}},
+		{[]int{4, 1, 1, 1, 1}, 2, []int{0, 1, 5}},
+		{[]int{0, 0, 0, 0}, 2, []int{0, 2, 4}},
+		{[]int{5}, 3, []int{0, 1, 1, 1}},
+	}
+	for _, c := range cases {
+		ranges, err := graph.WeightedRanges(c.weights, c.nParts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(ranges, c.ranges) {
+			t.Errorf("Ranges of %v into %d parts are %v, expected %v",
+				c.weights, c.nParts, ranges, c.ranges)
+		}
+	}
+
+	if _, err := graph.WeightedRanges([]int{1, -1}, 2); err == nil {
+		t.Errorf("WeightedRanges accepted a negative weight")
+	}
+}
+
+// TestExport checks that conflicting edges are highlighted in the DOT and
+// GraphML exports, that each edge is exported once, and that the palette
+// colors are distinct
+func TestExport(t *testing.T) {
+	g := graph.NewRingGraph(3)
+	g.Vertices[0].Value = 0
+	g.Vertices[1].Value = 1
+	g.Vertices[2].Value = 1
+
+	var buf bytes.Buffer
+	if err := g.DumpDOT(&buf); err != nil {
+		t.Fatal(err)
+	}
+	dot := buf.String()
+	if strings.Count(dot, "penwidth") != 1 ||
+		!strings.Contains(dot, "1 -- 2 [color=") {
+
+		t.Errorf("DOT export doesn't highlight conflict:\n%s", dot)
+	}
+
+	buf.Reset()
+	if err := g.DumpGraphML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(buf.String(), "<data key=\"conflict\">true") != 1 {
+		t.Errorf("GraphML export doesn't highlight conflict:\n%s",
+			buf.String())
+	}
+
+	// edges stored in one direction only (as in unsymmetrized input) are
+	// exported once, whichever endpoint lists them
+	h := graph.New(3)
+	h.Vertices[1].Adj = []int{0, 2}
+	h.Vertices[2].Adj = []int{1}
+	buf.Reset()
+	if err := h.DumpDOT(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(buf.String(), "--") != 2 ||
+		!strings.Contains(buf.String(), "0 -- 1") {
+
+		t.Errorf("DOT export of asymmetric edges is wrong:\n%s",
+			buf.String())
+	}
+	buf.Reset()
+	if err := h.DumpGraphML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(buf.String(), "<edge ") != 2 {
+		t.Errorf("GraphML export of asymmetric edges is wrong:\n%s",
+			buf.String())
+	}
+
+	palette := graph.Palette(100)
+	seen := make(map[string]bool)
+	for _, color := range palette {
+		if seen[color] {
+			t.Errorf("Palette color %s repeated", color)
+		}
+		seen[color] = true
+	}
+}
+
+// TestCompression checks that Load transparently reads gzip- and
+// zstd-compressed text and binary graphs, including the compressed copies of
+// the sample graphs in res/
+func TestCompression(t *testing.T) {
+	N := 1000
+	deg := float32(30)
+
+	g := graph.NewRandomGraph(N, deg, 1)
+
+	for _, compression := range []graph.Compression{graph.COMPRESSION_GZIP,
+		graph.COMPRESSION_ZSTD} {
+
+		var textBuf, binBuf bytes.Buffer
+		if err := g.DumpCompressed(&textBuf, compression); err != nil {
+			t.Fatal(err)
+		}
+		compressor, err := graph.Compress(&binBuf, compression)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := g.DumpBinary(compressor, false); err != nil {
+			t.Fatal(err)
+		}
+		if err := compressor.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		for name, buf := range map[string]*bytes.Buffer{
+			"text":   &textBuf,
+			"binary": &binBuf,
+		} {
+			bufReader := bufio.NewReader(bytes.NewReader(buf.Bytes()))
+			if graph.DetectCompression(bufReader) != compression {
+				t.Errorf("%s (compression %d): compression not detected",
+					name, compression)
+			}
+			h, err := graph.Load(buf)
+			if err != nil {
+				t.Fatalf("%s (compression %d): %v", name, compression, err)
+			}
+			if dumpString(*h) != dumpString(g) {
+				t.Errorf("%s (compression %d): loaded graph differs", name,
+					compression)
+			}
+		}
+	}
+
+	// the compressed sample graphs were written by the gzip and zstd tools
+	load := func(name string) graph.Graph {
+		file, err := os.Open("../../res/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		h, err := graph.Load(file)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		return *h
+	}
+	expected := dumpString(load("sample1000.graph"))
+	for _, name := range []string{"sample1000.graph.gz",
+		"sample1000.graph.zst"} {
+
+		if dumpString(load(name)) != expected {
+			t.Errorf("%s differs from sample1000.graph", name)
+		}
+	}
+}
+
 // BenchmarkNewGraph benches the time to generate a new graph
 // and number its nodes
 func BenchmarkNewGraph(b *testing.B) {
@@ -135,7 +1843,7 @@ func BenchmarkNewRandomGraph(b *testing.B) {
 	deg := float32(1000)
 
 	for i := 0; i < b.N; i++ {
-		graph.NewRandomGraph(N, deg)
+		graph.NewRandomGraph(N, deg, uint64(i))
 	}
 }
 
@@ -146,93 +1854,251 @@ func BenchmarkNewRandomGraphParallel(b *testing.B) {
 	deg := float32(1000)
 
 	for i := 0; i < b.N; i++ {
-		graph.NewRandomGraphParallel(N, deg, 50)
+		graph.NewRandomGraphParallel(N, deg, uint64(i), 50)
+	}
+}
+
+// BenchmarkNewRandomGraphParallelSparse benches the time it takes to
+// generate a large sparse random graph in parallel
+func BenchmarkNewRandomGraphParallelSparse(b *testing.B) {
+	N := 1000000
+	deg := float32(10)
+
+	for i := 0; i < b.N; i++ {
+		graph.NewRandomGraphParallel(N, deg, uint64(i), 50)
+	}
+}
+
+// benchmarkColoring is a helper for the BenchmarkColor* benchmarks, which
+// run the colorer registered under a name with a Delta+1 color bound
+func benchmarkColoring(b *testing.B, N int, deg float32, name string) {
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		g := graph.NewRandomGraphParallel(N, deg, uint64(i), 50)
+		opts := color.Options{MaxColor: stats.MaxDegree(&g) + 1}
+		b.StartTimer()
+
+		_, err := color.Run(context.Background(), name, &g, opts)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// benchmarkColoringCSR is a helper for BenchmarkColorCSR, which runs the
+// colorer registered under a name on the CSR layout of a random graph with a
+// Delta+1 color bound
+func benchmarkColoringCSR(b *testing.B, N int, deg float32, name string) {
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		g := graph.NewRandomGraphParallel(N, deg, uint64(i), 50)
+		c := graph.NewCSR(&g)
+		opts := color.Options{MaxColor: stats.MaxDegree(c) + 1}
+		b.StartTimer()
+
+		_, err := color.Run(context.Background(), name, c, opts)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkOrderings benchmarks sequential and parallel coloring in every
+// vertex order on a skewed (Barabasi-Albert) graph, reporting the number of
+// colors used, e.g., -bench 'Orderings/gm2/smallest-last'
+func BenchmarkOrderings(b *testing.B) {
+	const N, m = 100000, 10
+	g := graph.NewBarabasiAlbertGraphParallel(N, m, 1, 50)
+
+	for _, name := range []string{"sequential", "gm2"} {
+		for _, ordering := range color.Orderings() {
+			b.Run(name+"/"+ordering.String(), func(b *testing.B) {
+				var result *color.Result
+				var err error
+				for i := 0; i < b.N; i++ {
+					b.StopTimer()
+					for j := range g.Vertices {
+						g.Vertices[j].Value = 0
+					}
+					b.StartTimer()
+
+					result, err = color.Run(context.Background(), name, &g,
+						color.Options{Ordering: ordering, Seed: uint64(i)})
+					if err != nil {
+						b.Fatal(err)
+					}
+				}
+				b.ReportMetric(float64(result.NumColors), "colors")
+			})
+		}
 	}
 }
 
-type coloringAlgorithm = func(*graph.Graph, int)
+// benchmarkColorers is a helper for benchmarking colorers by name on random
+// graphs, reporting the number of colors and of rounds used
+func benchmarkColorers(b *testing.B, names []string, N int, deg float32) {
+	for _, name := range names {
+		b.Run(name, func(b *testing.B) {
+			nColors, nRounds := 0, 0
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				g := graph.NewRandomGraphParallel(N, deg, uint64(i), 50)
+				b.StartTimer()
 
-// benchmarkColoring is a helper for the BenchmarkColor* benchmarks
-func benchmarkColoring(b *testing.B, N int, deg float32, ca coloringAlgorithm) {
-	maxColor := 3 * int(deg) / 2
+				result, err := color.Run(context.Background(), name, &g,
+					color.Options{})
+				if err != nil {
+					b.Fatal(err)
+				}
+				nColors += result.NumColors
+				nRounds += result.Rounds
+			}
+			b.ReportMetric(float64(nColors)/float64(b.N), "colors")
+			b.ReportMetric(float64(nRounds)/float64(b.N), "rounds")
+		})
+	}
+}
+
+// BenchmarkColorers benchmarks every registered colorer by name, e.g.,
+// -bench 'Colorers/gm2$' selects ColorParallelGM2 (e.g., against the dsatur
+// baseline for colors)
+func BenchmarkColorers(b *testing.B) {
+	benchmarkColorers(b, color.Names(), 10000, 100)
+}
+
+// BenchmarkColorParallelDense compares the speculative (Gebremedhin-Manne)
+// and independent set (Jones-Plassmann) parallel colorers on a dense graph,
+// where speculation causes the most conflicts
+func BenchmarkColorParallelDense(b *testing.B) {
+	benchmarkColorers(b, []string{"gm", "gm2", "jp", "jp-ldf"}, 5000, 1000)
+}
+
+// benchmarkColoringMaxColor is a helper for the BenchmarkColor*MaxColor
+// benchmarks, which color sparse graphs with a loose color bound: the
+// forbidden-color tracking should only cost O(degree) per vertex, however
+// large maxColor is
+func benchmarkColoringMaxColor(b *testing.B, name string) {
+	const N, deg, maxColor = 10000, 10, 1 << 20
 
 	for i := 0; i < b.N; i++ {
 		b.StopTimer()
-		g := graph.NewRandomGraphParallel(N, deg, 50)
+		g := graph.NewRandomGraphParallel(N, deg, uint64(i), 50)
 		b.StartTimer()
 
-		ca(&g, maxColor)
+		_, err := color.Run(context.Background(), name, &g,
+			color.Options{MaxColor: maxColor})
+		if err != nil {
+			b.Fatal(err)
+		}
 	}
 }
 
+// BenchmarkColorSequentialMaxColor benchmarks sequential coloring with a
+// loose color bound
+func BenchmarkColorSequentialMaxColor(b *testing.B) {
+	benchmarkColoringMaxColor(b, "sequential")
+}
+
+// BenchmarkColorParallelGMMaxColor benchmarks parallel coloring (one
+// goroutine per node) with a loose color bound
+func BenchmarkColorParallelGMMaxColor(b *testing.B) {
+	benchmarkColoringMaxColor(b, "gm")
+}
+
+// BenchmarkColorParallelGM2MaxColor benchmarks parallel coloring (grouped
+// nodes) with a loose color bound
+func BenchmarkColorParallelGM2MaxColor(b *testing.B) {
+	benchmarkColoringMaxColor(b, "gm2")
+}
+
 // BenchmarkColorSequentialV100Bf10 benchmarks parallel coloring with 100
 // nodes and average branching factor of 10
 func BenchmarkColorSequentialV100Bf10(b *testing.B) {
-	benchmarkColoring(b, 100, 10, sequential.ColorSequential)
+	benchmarkColoring(b, 100, 10, "sequential")
 }
 
 // BenchmarkColorSequentialV1000Bf100 benchmarks parallel coloring with
 // 1000 nodes and average branching factor of 100
 func BenchmarkColorSequentialV1000Bf100(b *testing.B) {
-	benchmarkColoring(b, 1000, 100, sequential.ColorSequential)
+	benchmarkColoring(b, 1000, 100, "sequential")
 }
 
 // BenchmarkColorSequentialV10000Bf1000 benchmarks parallel coloring with
 // 10000 nodes and average branching factor of 1000
 func BenchmarkColorSequentialV10000Bf1000(b *testing.B) {
-	benchmarkColoring(b, 10000, 1000, sequential.ColorSequential)
+	benchmarkColoring(b, 10000, 1000, "sequential")
 }
 
 // BenchmarkColorSequentialV50000Bf5000 benchmarks parallel coloring with
 // 50000 nodes and average branching factor of 5000
 func BenchmarkColorSequentialV50000Bf5000(b *testing.B) {
-	benchmarkColoring(b, 50000, 5000, sequential.ColorSequential)
+	benchmarkColoring(b, 50000, 5000, "sequential")
 }
 
 // BenchmarkColorParallelGMV100Bf10 benchmarks parallel coloring with 100
 // nodes and average branching factor of 10
 func BenchmarkColorParallelGMV100Bf10(b *testing.B) {
-	benchmarkColoring(b, 100, 10, parallel.ColorParallelGM)
+	benchmarkColoring(b, 100, 10, "gm")
 }
 
 // BenchmarkColorParallelGMV1000Bf100 benchmarks parallel coloring with
 // 1000 nodes and average branching factor of 100
 func BenchmarkColorParallelGMV1000Bf100(b *testing.B) {
-	benchmarkColoring(b, 1000, 100, parallel.ColorParallelGM)
+	benchmarkColoring(b, 1000, 100, "gm")
 }
 
 // BenchmarkColorParallelGMV10000Bf1000 benchmarks parallel coloring with
 // 10000 nodes and average branching factor of 1000
 func BenchmarkColorParallelGMV10000Bf1000(b *testing.B) {
-	benchmarkColoring(b, 10000, 1000, parallel.ColorParallelGM)
+	benchmarkColoring(b, 10000, 1000, "gm")
 }
 
 // BenchmarkColorParallelGMV50000Bf5000 benchmarks parallel coloring with
 // 50000 nodes and average branching factor of 5000
 func BenchmarkColorParallelGMV50000Bf5000(b *testing.B) {
-	benchmarkColoring(b, 50000, 5000, parallel.ColorParallelGM)
+	benchmarkColoring(b, 50000, 5000, "gm")
 }
 
 // BenchmarkColorParallelGM2V100Bf10 benchmarks parallel coloring with 100
 // nodes and average branching factor of 10
 func BenchmarkColorParallelGM2V100Bf10(b *testing.B) {
-	benchmarkColoring(b, 100, 10, parallel.ColorParallelGM2)
+	benchmarkColoring(b, 100, 10, "gm2")
 }
 
 // BenchmarkColorParallelGM2V1000Bf100 benchmarks parallel coloring with
 // 1000 nodes and average branching factor of 100
 func BenchmarkColorParallelGM2V1000Bf100(b *testing.B) {
-	benchmarkColoring(b, 1000, 100, parallel.ColorParallelGM2)
+	benchmarkColoring(b, 1000, 100, "gm2")
 }
 
 // BenchmarkColorParallelGM2V10000Bf1000 benchmarks parallel coloring with
 // 10000 nodes and average branching factor of 1000
 func BenchmarkColorParallelGM2V10000Bf1000(b *testing.B) {
-	benchmarkColoring(b, 10000, 1000, parallel.ColorParallelGM2)
+	benchmarkColoring(b, 10000, 1000, "gm2")
 }
 
 // BenchmarkColorParallelGM2V50000Bf5000 benchmarks parallel coloring with
 // 50000 nodes and average branching factor of 5000
 func BenchmarkColorParallelGM2V50000Bf5000(b *testing.B) {
-	benchmarkColoring(b, 50000, 5000, parallel.ColorParallelGM2)
+	benchmarkColoring(b, 50000, 5000, "gm2")
+}
+
+// BenchmarkColorCSR benchmarks the colorers specialized to the CSR layout
+// on the same graph sizes as the BenchmarkColor*V*Bf* benchmarks, e.g.,
+// -bench 'ColorCSR/gm-csr/V1000Bf100$' selects ColorParallelGMCSR with 1000
+// nodes and average branching factor of 100
+func BenchmarkColorCSR(b *testing.B) {
+	sizes := []struct {
+		N   int
+		deg float32
+	}{{100, 10}, {1000, 100}, {10000, 1000}, {50000, 5000}}
+
+	for _, name := range []string{"sequential-csr", "gm-csr", "gm2-csr"} {
+		for _, size := range sizes {
+			b.Run(fmt.Sprintf("%s/V%dBf%d", name, size.N, int(size.deg)),
+				func(b *testing.B) {
+					benchmarkColoringCSR(b, size.N, size.deg, name)
+				})
+		}
+	}
 }
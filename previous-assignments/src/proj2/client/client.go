@@ -2,16 +2,19 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"flag"
 	"fmt"
 	"graph"
+	"graphalgo/color"
 	"graphalgo/color/distributed"
 	"graphnet"
 	"net"
 	"proj2/common"
 	"runtime"
 	"strconv"
+	"strings"
 	"sync"
 )
 
@@ -23,6 +26,13 @@ func main() {
 	// get port number to listen on
 	port := flag.Int("port", 0, "Port to listen on")
 
+	// use the CSR graph layout for coloring
+	useCSR := flag.Bool("csr", false, "Color the CSR graph layout")
+
+	// coloring algorithm, by name in the colorer registry
+	algorithm := flag.String("algorithm", "distributed",
+		"Coloring algorithm (must be able to run on a worker)")
+
 	flag.Parse()
 
 	// set up logger
@@ -225,21 +235,46 @@ func main() {
 		nodeConn.Index = int(nodeIndex[0])
 	}
 
+	// receive vertex ranges of all workers (sent before the subgraph)
+	dispatchTab[graphnet.MSG_VERTEX_RANGES] = func(buf []byte,
+		_ *graphnet.NodeConn) {
+
+		starts := strings.Split(string(buf), ",")
+		ws.Ranges = make([]int, len(starts))
+		for i, start := range starts {
+			var err error
+			ws.Ranges[i], err = strconv.Atoi(start)
+			if err != nil {
+				logger.Fatal(err)
+			}
+		}
+		logger.Printf("Received vertex ranges %v\n", ws.Ranges)
+	}
+
 	// receive subgraph
 	dispatchTab[graphnet.MSG_SUBGRAPH] = func(buf []byte,
 		_ *graphnet.NodeConn) {
 
 		defer startColoringWg.Done()
 		logger.Printf("Receiving subgraph...\n")
-		ws.Subgraph, err = graph.Load(bytes.NewReader(buf))
+		subgraph, err := graph.Load(bytes.NewReader(buf))
 		if err != nil {
 			logger.Fatal(err)
 		}
 
-		// calculate start, end vertices
+		ws.Subgraph = subgraph
+		if *useCSR {
+			ws.Subgraph = graph.NewCSR(subgraph)
+		}
+
+		// look up start, end vertices
 		nodeIndexWg.Wait()
-		ws.VertexBegin = (ws.NodeIndex - 1) * len(ws.Subgraph.Vertices)
-		ws.VertexEnd = ws.VertexBegin + len(ws.Subgraph.Vertices)
+		ws.VertexBegin = ws.Ranges[ws.NodeIndex-1]
+		ws.VertexEnd = ws.Ranges[ws.NodeIndex]
+		if ws.VertexEnd-ws.VertexBegin != ws.Subgraph.NumVertices() {
+			logger.Fatalf("Subgraph has %d vertices, expected %d\n",
+				ws.Subgraph.NumVertices(), ws.VertexEnd-ws.VertexBegin)
+		}
 		logger.Printf("Finished receiving subgraph (vertices %d-%d).\n",
 			ws.VertexBegin, ws.VertexEnd-1)
 	}
@@ -291,9 +326,17 @@ func main() {
 	startColoringWg.Wait()
 	logger.Printf("Beginning coloring...\n")
 
-	ws.State = distributed.STATE_RUNNING
-	distributed.ColorDistributed(ws, 10000, runtime.NumCPU()*2, logger)
-	ws.State = distributed.STATE_FINISHED
+	// no bound on the number of colors: remote neighbors may have any color,
+	// but greedy coloring never needs more than Delta+1
+	result, err := distributed.RunWorker(context.Background(), *algorithm,
+		ws, color.Options{NThreads: runtime.NumCPU() * 2}, logger)
+	if err != nil {
+		logger.Printf("Coloring failed: %v\n", err)
+	} else {
+		logger.Printf("Colored with %d colors in %d rounds (%d conflicts) "+
+			"in %v.\n", result.NumColors, result.Rounds, result.Conflicts,
+			result.Elapsed)
+	}
 	logger.Printf("Done.")
 
 	// hang around to prevent broken read/writes
@@ -15,11 +15,12 @@ func main() {
 	// params for sample graph
 	nVertices := 100
 	degree := float32(10)
+	seed := uint64(0)
 	nThreads := 2 * runtime.NumCPU()
 	outFile := fmt.Sprintf("res/sample%d.graph", nVertices)
 
 	// generate some sample graphs for use as testcases
-	g := graph.NewRandomGraphParallel(nVertices, degree, nThreads)
+	g := graph.NewRandomGraphParallel(nVertices, degree, seed, nThreads)
 
 	// write file
 	log.Printf("Creating graph file %s...\n", outFile)
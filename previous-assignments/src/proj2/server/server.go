@@ -4,9 +4,9 @@ import (
 	"bufio"
 	"encoding/binary"
 	"flag"
-	"fmt"
+	"graph"
 	"graphnet"
-	"math"
+	"io"
 	"net"
 	"os"
 	"proj2/common"
@@ -134,45 +134,71 @@ func main() {
 	// for the server
 	ncp.Register()
 
-	// divide graph into equally-sized subgraphs (padding with empty nodes
-	// as necessary) and streaming
+	// divide graph into subgraphs of contiguous vertices with about equal
+	// total vertex weight (equally-sized for unweighted graphs), and stream
+	// them; the graph file is read twice, first for the vertex weights
 	// TODO: should probably move this to its own function
+	weights, err := readVertexWeights(*graphFile)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	ranges, err := graph.WeightedRanges(weights, nWorkers)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	// every worker needs all ranges, to know where to send vertex info
+	rangeStrings := make([]string, len(ranges))
+	for i, start := range ranges {
+		rangeStrings[i] = strconv.Itoa(start)
+	}
+	for i, nodeConn := range ncp.Conns {
+		if i == 0 {
+			continue
+		}
+		nodeConn.WriteBytes(graphnet.MSG_VERTEX_RANGES,
+			[]byte(strings.Join(rangeStrings, ",")), true)
+		nodeConn.WriteBytes(graphnet.DELIM_EOF, buf[:0], false)
+	}
+
 	file, err = os.Open(*graphFile)
 	if err != nil {
 		logger.Fatal(err)
 	}
-	scanner := bufio.NewScanner(file)
-	scanner.Scan()
-	nVertices, err := strconv.Atoi(scanner.Text())
+	// graph files may be compressed or binary, which is transparent to the
+	// workers: subgraphs are always sent in the text format
+	vertexReader, err := graph.NewVertexReader(file)
 	if err != nil {
 		logger.Fatal(err)
 	}
-	verticesPerNode := int(math.Ceil(float64(nVertices) / float64(nWorkers)))
+
 	for i, nodeConn := range ncp.Conns {
 		if i == 0 {
 			continue
 		}
+		begin, end := ranges[i-1], ranges[i]
 
-		// begin writing file
+		// begin writing file; subgraphs keep the graph's attributes, and
+		// vertices missing from the file are sent as disconnected vertices
 		nodeConn.WriteBytes(graphnet.MSG_SUBGRAPH, buf[:0], true)
-
-		nodeConn.WriteBytes(graphnet.MSG_CONT,
-			[]byte(fmt.Sprintf("%d", verticesPerNode)), true)
-
-		// send vertices, pad with disconnected vertices if necessary
-		for j := 0; j < verticesPerNode; j++ {
-			nodeConn.WriteBytes(graphnet.MSG_CONT, []byte("\n"), true)
-			if scanner.Scan() {
-				nodeConn.WriteBytes(graphnet.MSG_CONT, scanner.Bytes(),
-					true)
-			} else {
-				nodeConn.WriteBytes(graphnet.MSG_CONT, []byte("0;"),
-					true)
-			}
+		err = vertexReader.WriteSubgraph(subgraphWriter{nodeConn},
+			end-begin)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		nodeWeight := 0
+		for j := begin; j < end; j++ {
+			nodeWeight += weights[j]
 		}
 
 		// end write file
 		nodeConn.WriteBytes(graphnet.DELIM_EOF, buf[:0], false)
+		logger.Printf("Sent subgraph of vertices %d-%d to node %d (total "+
+			"vertex weight %d)\n", begin, end-1, i, nodeWeight)
+	}
+	err = file.Close()
+	if err != nil {
+		logger.Fatal(err)
 	}
 
 	// wait for all nodes to finish handshake
@@ -190,3 +216,47 @@ func main() {
 
 	logger.Printf("Done.")
 }
+
+// subgraphWriter writes a subgraph to a node as the continuation of a
+// MSG_SUBGRAPH message
+type subgraphWriter struct {
+	nodeConn *graphnet.NodeConn
+}
+
+func (w subgraphWriter) Write(p []byte) (int, error) {
+	w.nodeConn.WriteBytes(graphnet.MSG_CONT, p, true)
+	return len(p), nil
+}
+
+// readVertexWeights reads the weight of each vertex of a (possibly
+// compressed or binary) graph file without loading the graph, which is 1 for
+// unweighted graphs and for vertices missing from the file; this also checks
+// binary files before anything is sent
+func readVertexWeights(graphFile string) ([]int, error) {
+	file, err := os.Open(graphFile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	vertexReader, err := graph.NewVertexReader(file)
+	if err != nil {
+		return nil, err
+	}
+
+	weights := make([]int, vertexReader.NumVertices)
+	for i := range weights {
+		line, err := vertexReader.Next()
+		if err != nil {
+			return nil, err
+		}
+		weights[i], err = graph.TextVertexWeight(line, vertexReader.Flags)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if _, err := vertexReader.Next(); err != io.EOF {
+		return nil, err
+	}
+	return weights, nil
+}